@@ -3,4 +3,5 @@ module github.com/fretlink/terraform-provider-mailgun
 require (
 	github.com/hashicorp/terraform v0.12.3
 	github.com/mailgun/mailgun-go/v3 v3.6.0
+	golang.org/x/net v0.0.0-20190502183928-7f726cade0ab
 )
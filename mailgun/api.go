@@ -0,0 +1,86 @@
+package mailgun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mailgun/mailgun-go/v3"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// expectedStatus lists the status codes of successful Mailgun API responses.
+var expectedStatus = []int{http.StatusOK, http.StatusAccepted, http.StatusNoContent}
+
+var apiVersionSuffix = regexp.MustCompile(`/v[0-9]+/?$`)
+
+// apiRoot returns the API base URL of a client without its version, e.g. https://api.mailgun.net
+func apiRoot(mg *mailgun.MailgunImpl) string {
+	return apiVersionSuffix.ReplaceAllString(mg.APIBase(), "")
+}
+
+// apiRequest calls a Mailgun endpoint which is not covered by mailgun-go.
+// path includes the API version (e.g. "/v1/keys"); params are sent as the query string
+// of GET and DELETE requests and url-encoded in the body otherwise.
+// Unexpected status codes are reported as *mailgun.UnexpectedResponseError, like mailgun-go does.
+func apiRequest(ctx context.Context, mg *mailgun.MailgunImpl, method, path string, params url.Values, out interface{}) error {
+	endpoint := apiRoot(mg) + path
+
+	var body io.Reader
+	if method == http.MethodGet || method == http.MethodDelete {
+		if len(params) > 0 {
+			endpoint += "?" + params.Encode()
+		}
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.Header.Set("User-Agent", mailgun.MailgunGoUserAgent)
+	req.SetBasicAuth("api", mg.APIKey())
+
+	resp, err := mg.Client().Do(req)
+	if err != nil {
+		return fmt.Errorf("while making http request: %s", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("while reading response body: %s", err)
+	}
+
+	if !isExpectedStatus(resp.StatusCode) {
+		return &mailgun.UnexpectedResponseError{
+			Expected: expectedStatus,
+			Actual:   resp.StatusCode,
+			URL:      strings.SplitN(endpoint, "?", 2)[0],
+			Data:     data,
+		}
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func isExpectedStatus(code int) bool {
+	for _, c := range expectedStatus {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
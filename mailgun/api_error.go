@@ -0,0 +1,118 @@
+package mailgun
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mailgun/mailgun-go/v3"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxErrorMessage is the number of characters of a raw Mailgun response reported at most in an error.
+const maxErrorMessage = 500
+
+// APIError reports a failed call to the Mailgun API while managing a resource,
+// with the HTTP status and the message of Mailgun when it responded.
+type APIError struct {
+	// Operation is what was being done, e.g. "creating" or "reading the credentials of".
+	Operation string
+	// Resource is the type of the resource followed by its name or ID when known, e.g. "mailgun_domain example.com".
+	Resource string
+	// StatusCode is the HTTP status of the response, 0 when Mailgun did not respond.
+	StatusCode int
+	// Message is the message of Mailgun, or the error met when it did not respond.
+	Message string
+	// Hint suggests how to fix the most common causes of the error, if any.
+	Hint string
+	// Err is the original error.
+	Err error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("Error %s %s: ", e.Operation, e.Resource)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf("Mailgun responded %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+		if e.Message != "" {
+			msg += ": " + e.Message
+		}
+	} else {
+		msg += e.Message
+	}
+	if e.Hint != "" {
+		msg += ". " + e.Hint
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// newAPIError wraps err, returned by the Mailgun API while doing operation on resource, in an *APIError.
+// It returns nil when err is nil.
+func newAPIError(operation, resource string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e := &APIError{Operation: operation, Resource: resource, Message: err.Error(), Err: err}
+	if resp, ok := err.(*mailgun.UnexpectedResponseError); ok {
+		e.StatusCode = resp.Actual
+		e.Message = responseMessage(resp.Data)
+		e.Hint = statusHint(resp.Actual, resp.URL)
+	}
+	return e
+}
+
+// responseMessage returns the message of a Mailgun error response, which is usually
+// a JSON object with a message, or else plain text.
+func responseMessage(data []byte) string {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil && body.Message != "" {
+		return body.Message
+	}
+
+	msg := strings.TrimSpace(string(data))
+	if len(msg) > maxErrorMessage {
+		msg = msg[:maxErrorMessage] + "..."
+	}
+	return msg
+}
+
+// statusHint suggests how to fix the usual causes of an error status returned by endpoint.
+func statusHint(status int, endpoint string) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return "Check the apikey of the provider, or MAILGUN_APIKEY: it may be mistyped, disabled or revoked" + regionHint(endpoint, "keys")
+	case status == http.StatusForbidden:
+		return "The API key is not allowed to do this: domain sending keys can only send messages, " +
+			"and subaccount_id must be a subaccount of the account of the key"
+	case status == http.StatusNotFound:
+		return "Check that it exists in the account, or in the subaccount set by subaccount_id" + regionHint(endpoint, "domains")
+	case status == http.StatusTooManyRequests:
+		return "Mailgun rate limits the account: set rate_limit on the provider to space out the requests"
+	case status >= 500:
+		return "Mailgun failed to process the request, try again later"
+	}
+	return ""
+}
+
+// regionHint reminds that the keys or domains of a Mailgun region are not known to the API of the other one,
+// and how to switch the provider to the other region.
+func regionHint(endpoint, what string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	for region, base := range regionAPIBases {
+		if b, err := url.Parse(base); err == nil && b.Host == u.Host {
+			other := otherRegion(region)
+			return fmt.Sprintf("; the provider uses the %s region, set region = %q on the provider, or MAILGUN_REGION, for the %s of the %s region",
+				strings.ToUpper(region), other, what, strings.ToUpper(other))
+		}
+	}
+	return ""
+}
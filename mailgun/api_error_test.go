@@ -0,0 +1,59 @@
+package mailgun
+
+import (
+	"fmt"
+	"github.com/mailgun/mailgun-go/v3"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected string
+		hint     string
+	}{
+		{
+			&mailgun.UnexpectedResponseError{Actual: http.StatusUnauthorized, URL: "https://api.mailgun.net/v3/domains", Data: []byte("Forbidden")},
+			"Error creating mailgun_domain example.com: Mailgun responded 401 Unauthorized: Forbidden. Check the apikey",
+			`set region = "eu" on the provider, or MAILGUN_REGION, for the keys of the EU region`,
+		},
+		{
+			&mailgun.UnexpectedResponseError{Actual: http.StatusNotFound, URL: "https://api.eu.mailgun.net/v3/domains", Data: []byte(`{"message": "Domain not found"}`)},
+			"Error creating mailgun_domain example.com: Mailgun responded 404 Not Found: Domain not found. Check that it exists",
+			`the provider uses the EU region, set region = "us"`,
+		},
+		{
+			&mailgun.UnexpectedResponseError{Actual: http.StatusBadRequest, URL: "https://api.mailgun.net/v3/domains", Data: []byte(`{"message": "Invalid spam_action"}`)},
+			"Error creating mailgun_domain example.com: Mailgun responded 400 Bad Request: Invalid spam_action",
+			"",
+		},
+		{
+			fmt.Errorf("connection refused"),
+			"Error creating mailgun_domain example.com: connection refused",
+			"",
+		},
+	}
+
+	for _, c := range cases {
+		err := newAPIError("creating", "mailgun_domain example.com", c.err)
+		if !strings.HasPrefix(err.Error(), c.expected) {
+			t.Errorf("expected %q to start with %q", err, c.expected)
+		}
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			t.Fatalf("expected an *APIError, got %T", err)
+		}
+		if !strings.Contains(apiErr.Hint, c.hint) || (c.hint == "" && apiErr.Hint != "") {
+			t.Errorf("expected the hint of %q to contain %q, got %q", err, c.hint, apiErr.Hint)
+		}
+		if apiErr.Err != c.err {
+			t.Errorf("expected %q to wrap %q", err, c.err)
+		}
+	}
+
+	if err := newAPIError("creating", "mailgun_domain example.com", nil); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+}
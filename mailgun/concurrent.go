@@ -0,0 +1,48 @@
+package mailgun
+
+import (
+	"context"
+	"sync"
+)
+
+// domainReadConcurrency bounds the number of requests sent concurrently to read a domain.
+var domainReadConcurrency = 4
+
+// runConcurrently runs fns with at most limit of them at a time. The context given to fns is
+// cancelled as soon as one of them fails, and the first error is returned.
+func runConcurrently(ctx context.Context, limit int, fns ...func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if limit < 1 {
+		limit = 1
+	}
+	slots := make(chan struct{}, limit)
+	errs := make(chan error, len(fns))
+	var wg sync.WaitGroup
+	for _, fn := range fns {
+		wg.Add(1)
+		go func(fn func(context.Context) error) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+			if ctx.Err() != nil {
+				errs <- ctx.Err()
+				return
+			}
+			if err := fn(ctx); err != nil {
+				errs <- err
+				cancel()
+			}
+		}(fn)
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
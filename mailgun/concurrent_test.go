@@ -0,0 +1,58 @@
+package mailgun
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunConcurrently_limit(t *testing.T) {
+	var running, max int32
+	fn := func(ctx context.Context) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+
+	if err := runConcurrently(context.Background(), 2, fn, fn, fn, fn, fn); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if max != 2 {
+		t.Errorf("expected 2 functions to run at a time, got %d", max)
+	}
+}
+
+func TestRunConcurrently_firstErrorCancels(t *testing.T) {
+	failure := errors.New("failure")
+	start := time.Now()
+
+	err := runConcurrently(context.Background(), 3,
+		func(ctx context.Context) error {
+			return failure
+		},
+		func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		},
+	)
+
+	if err != failure {
+		t.Errorf("expected the first error, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected the other function to be cancelled")
+	}
+}
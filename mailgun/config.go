@@ -0,0 +1,222 @@
+package mailgun
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/logging"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/mailgun/mailgun-go/v3"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// subaccountHeader makes the primary account act on behalf of one of its subaccounts.
+const subaccountHeader = "X-Mailgun-On-Behalf-Of"
+
+// defaultMaxIdleConns is the default size of the connection pool shared by all the clients.
+const defaultMaxIdleConns = 100
+
+// Mailgun regions, each served by its own API which only knows the accounts of the region.
+const (
+	regionUS = "us"
+	regionEU = "eu"
+)
+
+// regionAPIBases maps the regions to the base URL of their API.
+var regionAPIBases = map[string]string{
+	regionUS: "https://api.mailgun.net/v3",
+	regionEU: "https://api.eu.mailgun.net/v3",
+}
+
+// Config holds the provider configuration and hands out Mailgun clients to the resources.
+// All the clients share a single http.Client, and thus a single connection pool.
+type Config struct {
+	APIKey       string
+	Domain       string
+	SubaccountID string
+	MaxIdleConns int
+	UserAgent    string
+	ReadOnly     bool
+	// Region is the Mailgun region of the account, us when empty.
+	Region string
+	// RateLimit is the maximum number of requests per second sent by the provider, unlimited when 0.
+	RateLimit float64
+	// ReadCacheTTL is how long the responses of the list endpoints are cached, not at all when 0.
+	ReadCacheTTL time.Duration
+
+	// apiBase overrides the base URL of the Mailgun API, e.g. to test against a local server.
+	apiBase string
+
+	httpClientOnce sync.Once
+	httpClient     *http.Client
+}
+
+// Client returns a client for domain acting on behalf of subaccountID,
+// or of the subaccount configured on the provider when subaccountID is empty.
+func (c *Config) Client(domain, subaccountID string) *mailgun.MailgunImpl {
+	if subaccountID == "" {
+		subaccountID = c.SubaccountID
+	}
+
+	mg := mailgun.NewMailgun(domain, c.APIKey)
+	mg.SetAPIBase(c.APIBase())
+	httpClient := c.HTTPClient()
+	if subaccountID != "" {
+		httpClient = &http.Client{
+			Transport: &subaccountTransport{subaccountID: subaccountID, next: httpClient.Transport},
+			Timeout:   httpClient.Timeout,
+		}
+	}
+	mg.SetClient(httpClient)
+	return mg
+}
+
+// PrimaryClient returns a client acting on the primary account itself, regardless of any subaccount.
+func (c *Config) PrimaryClient() *mailgun.MailgunImpl {
+	mg := mailgun.NewMailgun(c.Domain, c.APIKey)
+	mg.SetAPIBase(c.APIBase())
+	mg.SetClient(c.HTTPClient())
+	return mg
+}
+
+// RegionName returns the Mailgun region of the account, us by default.
+func (c *Config) RegionName() string {
+	if c.Region == "" {
+		return regionUS
+	}
+	return c.Region
+}
+
+// APIBase returns the base URL of the Mailgun API of the region of the account.
+func (c *Config) APIBase() string {
+	if c.apiBase != "" {
+		return c.apiBase
+	}
+	return regionAPIBases[c.RegionName()]
+}
+
+// HTTPClient returns the http.Client shared by all the Mailgun clients, building it on first use.
+// It honours the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func (c *Config) HTTPClient() *http.Client {
+	c.httpClientOnce.Do(func() {
+		maxIdleConns := c.MaxIdleConns
+		if maxIdleConns <= 0 {
+			maxIdleConns = defaultMaxIdleConns
+		}
+
+		transport := &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:          maxIdleConns,
+			MaxIdleConnsPerHost:   maxIdleConns,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+
+		var roundTripper http.RoundTripper = transport
+		if logging.IsDebugOrHigher() {
+			roundTripper = &debugLogTransport{next: roundTripper}
+		}
+		if c.RateLimit > 0 {
+			roundTripper = &rateLimitTransport{limiter: newRateLimiter(c.RateLimit), next: roundTripper}
+		}
+		if c.ReadCacheTTL > 0 {
+			roundTripper = newReadCacheTransport(c.ReadCacheTTL, roundTripper)
+		}
+		if c.UserAgent != "" {
+			roundTripper = &userAgentTransport{userAgent: c.UserAgent, next: roundTripper}
+		}
+		if c.ReadOnly {
+			roundTripper = &readOnlyTransport{next: roundTripper}
+		}
+
+		c.httpClient = &http.Client{
+			Transport: roundTripper,
+			Timeout:   2 * time.Minute,
+		}
+	})
+	return c.httpClient
+}
+
+// ResolveDomain returns domain, falling back to the domain configured on the provider
+// for resources scoped to a domain which do not set one.
+func (c *Config) ResolveDomain(domain string) (string, error) {
+	if domain != "" {
+		return domain, nil
+	}
+	if c.Domain != "" {
+		return c.Domain, nil
+	}
+	return "", fmt.Errorf("no domain set: configure a domain on the resource or on the provider")
+}
+
+// resourceClient returns a client for domain honouring the subaccount_id of the resource.
+func resourceClient(d *schema.ResourceData, meta interface{}, domain string) *mailgun.MailgunImpl {
+	return meta.(*Config).Client(domain, d.Get("subaccount_id").(string))
+}
+
+func subaccountIDSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "Subaccount to manage the resource in, overriding the provider subaccount_id.",
+	}
+}
+
+// subaccountTransport adds the subaccount header to every request.
+type subaccountTransport struct {
+	subaccountID string
+	next         http.RoundTripper
+}
+
+func (t *subaccountTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := cloneRequest(req)
+	r.Header.Set(subaccountHeader, t.subaccountID)
+	return t.next.RoundTrip(r)
+}
+
+// userAgentTransport replaces the user agent set by mailgun-go with the one of the provider.
+type userAgentTransport struct {
+	userAgent string
+	next      http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := cloneRequest(req)
+	r.Header.Set("User-Agent", t.userAgent)
+	return t.next.RoundTrip(r)
+}
+
+// readOnlyTransport refuses the requests which may write, as a safety net of the read only mode.
+type readOnlyTransport struct {
+	next http.RoundTripper
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, fmt.Errorf("the provider is read only, refusing %s %s", req.Method, req.URL.Path)
+}
+
+// cloneRequest returns a shallow copy of req with its own headers,
+// as a RoundTripper must not modify the request it is given.
+func cloneRequest(req *http.Request) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	return r
+}
@@ -0,0 +1,119 @@
+package mailgun
+
+import (
+	"context"
+	"github.com/mailgun/mailgun-go/v3"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConfigClient_subaccountHeader(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(subaccountHeader)
+		w.Write([]byte(`{"connection": {"require_tls": false, "skip_verification": false}}`))
+	}))
+	defer server.Close()
+
+	config := &Config{APIKey: "key", SubaccountID: "provider-subaccount"}
+
+	cases := []struct {
+		subaccountID string
+		expected     string
+	}{
+		{"", "provider-subaccount"},
+		{"resource-subaccount", "resource-subaccount"},
+	}
+
+	for _, c := range cases {
+		mg := config.Client("domain.com", c.subaccountID)
+		mg.SetAPIBase(server.URL + "/v3")
+		if _, err := mg.GetDomainConnection(context.Background(), "domain.com"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got != c.expected {
+			t.Fatalf("expected subaccount header %q, got %q", c.expected, got)
+		}
+	}
+
+	mg := config.PrimaryClient()
+	mg.SetAPIBase(server.URL + "/v3")
+	if _, err := mg.GetDomainConnection(context.Background(), "domain.com"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got != "" {
+		t.Fatalf("expected no subaccount header for the primary account, got %q", got)
+	}
+}
+
+func TestConfigResolveDomain(t *testing.T) {
+	withDomain := &Config{APIKey: "key", Domain: "provider.com"}
+	withoutDomain := &Config{APIKey: "key"}
+
+	if domain, err := withDomain.ResolveDomain("resource.com"); err != nil || domain != "resource.com" {
+		t.Fatalf("expected the resource domain, got %q (%v)", domain, err)
+	}
+	if domain, err := withDomain.ResolveDomain(""); err != nil || domain != "provider.com" {
+		t.Fatalf("expected the provider domain, got %q (%v)", domain, err)
+	}
+	if domain, err := withoutDomain.ResolveDomain("resource.com"); err != nil || domain != "resource.com" {
+		t.Fatalf("expected the resource domain, got %q (%v)", domain, err)
+	}
+	if _, err := withoutDomain.ResolveDomain(""); err == nil {
+		t.Fatal("expected an error when no domain is set")
+	}
+}
+
+func TestConfigClient_sharedHTTPClient(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"connection": {"require_tls": false, "skip_verification": false}}`))
+	}))
+	defer server.Close()
+
+	config := &Config{APIKey: "key", UserAgent: "terraform-provider-mailgun/test"}
+
+	first := config.Client("first.com", "")
+	second := config.Client("second.com", "")
+	if first.Client() != second.Client() {
+		t.Fatal("expected the clients of all domains to share the same http.Client")
+	}
+
+	subaccount := config.Client("first.com", "subaccount")
+	if subaccount.Client().Transport.(*subaccountTransport).next != first.Client().Transport {
+		t.Fatal("expected subaccount clients to share the transport of the provider")
+	}
+
+	first.SetAPIBase(server.URL + "/v3")
+	if _, err := first.GetDomainConnection(context.Background(), "first.com"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if userAgent != "terraform-provider-mailgun/test" {
+		t.Fatalf("expected the provider user agent, got %q", userAgent)
+	}
+}
+
+func TestConfigClient_readOnly(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Write([]byte(`{"connection": {"require_tls": false, "skip_verification": false}}`))
+	}))
+	defer server.Close()
+
+	config := &Config{APIKey: "key", ReadOnly: true}
+	mg := config.Client("domain.com", "")
+	mg.SetAPIBase(server.URL + "/v3")
+
+	if _, err := mg.GetDomainConnection(context.Background(), "domain.com"); err != nil {
+		t.Fatalf("expected reads to be sent, got %s", err)
+	}
+	if err := mg.UpdateDomainConnection(context.Background(), "domain.com", mailgun.DomainConnection{RequireTLS: true}); err == nil {
+		t.Fatal("expected writes to be refused")
+	}
+	if len(methods) != 1 || methods[0] != http.MethodGet {
+		t.Fatalf("expected only the read to reach the server, got %v", methods)
+	}
+}
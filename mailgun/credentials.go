@@ -0,0 +1,199 @@
+package mailgun
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// defaultProfile is the profile of the shared credentials file used when none is set.
+const defaultProfile = "default"
+
+// apiKeySources holds the ways the API key may be given to the provider.
+type apiKeySources struct {
+	APIKey        string
+	APIKeyFile    string
+	APIKeyCommand string
+	Profile       string
+}
+
+// resolveAPIKey returns the API key from the first of its sources which is set, in this order:
+// the key itself, the file holding it, the command printing it, then the profile of the shared
+// credentials file, ~/.mailgun/credentials.
+func resolveAPIKey(sources apiKeySources) (string, error) {
+	switch {
+	case sources.APIKey != "":
+		return sources.APIKey, nil
+	case sources.APIKeyFile != "":
+		return readAPIKeyFile(sources.APIKeyFile)
+	case sources.APIKeyCommand != "":
+		return runAPIKeyCommand(sources.APIKeyCommand)
+	}
+
+	profile := sources.Profile
+	if profile == "" {
+		profile = defaultProfile
+	}
+	path, err := sharedCredentialsFile()
+	if err != nil {
+		return "", err
+	}
+	key, err := readProfileAPIKey(path, profile)
+	if os.IsNotExist(err) && sources.Profile == "" {
+		return "", fmt.Errorf("no API key: set apikey, apikey_file, apikey_command or a profile of %s", path)
+	}
+	return key, err
+}
+
+func readAPIKeyFile(path string) (string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Error reading apikey_file: %s", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("Error reading apikey_file: %s is empty", path)
+	}
+	return key, nil
+}
+
+// runAPIKeyCommand runs command with the shell and returns what it prints on its standard output.
+func runAPIKeyCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Error running apikey_command: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	key := strings.TrimSpace(string(out))
+	if key == "" {
+		return "", fmt.Errorf("Error running apikey_command: it printed no API key")
+	}
+	return key, nil
+}
+
+func sharedCredentialsFile() (string, error) {
+	return expandHome(filepath.Join("~", ".mailgun", "credentials"))
+}
+
+// readProfileAPIKey reads the apikey of profile in the shared credentials file at path,
+// an INI file with a section per profile:
+//
+//	[default]
+//	apikey = key-...
+func readProfileAPIKey(path, profile string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	section := ""
+	found := false
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";"):
+			continue
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			found = found || section == profile
+			continue
+		}
+
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return "", fmt.Errorf("Error reading %s: invalid line %d, expected key = value", path, line)
+		}
+		if section == profile && strings.TrimSpace(parts[0]) == "apikey" {
+			return strings.TrimSpace(parts[1]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("Error reading %s: %s", path, err)
+	}
+
+	if !found {
+		return "", fmt.Errorf("Error reading %s: no profile %s", path, profile)
+	}
+	return "", fmt.Errorf("Error reading %s: no apikey in profile %s", path, profile)
+}
+
+// expandHome replaces a leading ~ of path with the home directory of the user.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home := os.Getenv("HOME")
+	if home == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		home = u.HomeDir
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// validateCredentials checks that the API key of config is accepted by Mailgun, along with the
+// subaccount it acts on behalf of, with a cheap request listing a single domain.
+func validateCredentials(config *Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	mg := config.Client("", "")
+	params := url.Values{}
+	params.Set("limit", "1")
+	err := apiRequest(ctx, mg, http.MethodGet, "/v3/domains", params, nil)
+	if err == nil {
+		return nil
+	}
+
+	apiErr := newAPIError("validating the credentials of", "provider mailgun", err).(*APIError)
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		// Keys are only known to the API of the region of their account.
+		region := otherRegion(config.RegionName())
+		mg.SetAPIBase(regionAPIBases[region])
+		if apiRequest(ctx, mg, http.MethodGet, "/v3/domains", params, nil) == nil {
+			apiErr.Hint = fmt.Sprintf("The API key belongs to an account of the %s region: set region = %q on the provider, or MAILGUN_REGION",
+				strings.ToUpper(region), region)
+		}
+	case 0:
+		apiErr.Hint = "Set skip_credentials_validation to configure the provider without reaching Mailgun, e.g. to plan offline"
+	}
+	return apiErr
+}
+
+// otherRegion returns the other Mailgun region than region.
+func otherRegion(region string) string {
+	if region == regionEU {
+		return regionUS
+	}
+	return regionEU
+}
@@ -0,0 +1,133 @@
+package mailgun
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveAPIKey(t *testing.T) {
+	home, err := ioutil.TempDir("", "mailgun-credentials")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	if err := os.Mkdir(filepath.Join(home, ".mailgun"), 0700); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	credentials := "# shared credentials\n[default]\napikey = key-default\n\n[prod]\nregion = us\napikey=key-prod\n\n[empty]\n"
+	if err := ioutil.WriteFile(filepath.Join(home, ".mailgun", "credentials"), []byte(credentials), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(home, "apikey"), []byte("key-file\n"), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cases := []struct {
+		sources  apiKeySources
+		expected string
+		err      string
+	}{
+		{apiKeySources{APIKey: "key", APIKeyFile: "~/apikey", APIKeyCommand: "echo key-command", Profile: "prod"}, "key", ""},
+		{apiKeySources{APIKeyFile: "~/apikey", APIKeyCommand: "echo key-command", Profile: "prod"}, "key-file", ""},
+		{apiKeySources{APIKeyCommand: "echo key-command", Profile: "prod"}, "key-command", ""},
+		{apiKeySources{Profile: "prod"}, "key-prod", ""},
+		{apiKeySources{}, "key-default", ""},
+		{apiKeySources{APIKeyFile: "~/missing"}, "", "Error reading apikey_file"},
+		{apiKeySources{APIKeyCommand: "echo failure >&2; exit 1"}, "", "failure"},
+		{apiKeySources{APIKeyCommand: "true"}, "", "printed no API key"},
+		{apiKeySources{Profile: "staging"}, "", "no profile staging"},
+		{apiKeySources{Profile: "empty"}, "", "no apikey in profile empty"},
+	}
+
+	for _, c := range cases {
+		key, err := resolveAPIKey(c.sources)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%+v: expected an error containing %q, got %v", c.sources, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: err: %s", c.sources, err)
+		} else if key != c.expected {
+			t.Errorf("%+v: expected %q, got %q", c.sources, c.expected, key)
+		}
+	}
+}
+
+func TestResolveAPIKey_noSource(t *testing.T) {
+	home, err := ioutil.TempDir("", "mailgun-credentials")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	_, err = resolveAPIKey(apiKeySources{})
+	if err == nil || !strings.Contains(err.Error(), "no API key") {
+		t.Fatalf("expected a missing API key error, got %v", err)
+	}
+}
+
+// newFakeRegionServer returns a server answering the requests to list the domains made with key.
+func newFakeRegionServer(key string, subaccountID *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, k, _ := r.BasicAuth(); k != key {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Forbidden"))
+			return
+		}
+		*subaccountID = r.Header.Get(subaccountHeader)
+		w.Write([]byte(`{"total_count": 0, "items": []}`))
+	}))
+}
+
+func TestValidateCredentials(t *testing.T) {
+	var subaccountID string
+	us := newFakeRegionServer("key", &subaccountID)
+	defer us.Close()
+	eu := newFakeRegionServer("eu-key", &subaccountID)
+	defer eu.Close()
+
+	defer func(bases map[string]string) { regionAPIBases = bases }(regionAPIBases)
+	regionAPIBases = map[string]string{regionUS: us.URL + "/v3", regionEU: eu.URL + "/v3"}
+
+	if err := validateCredentials(&Config{APIKey: "key", SubaccountID: "subaccount"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if subaccountID != "subaccount" {
+		t.Errorf("expected the subaccount to be validated, got %q", subaccountID)
+	}
+	if err := validateCredentials(&Config{APIKey: "eu-key", Region: regionEU}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	err := validateCredentials(&Config{APIKey: "wrong"})
+	if err == nil || !strings.Contains(err.Error(), "401 Unauthorized") || !strings.Contains(err.Error(), "Check the apikey") {
+		t.Errorf("expected the key to be rejected, got %v", err)
+	}
+
+	err = validateCredentials(&Config{APIKey: "eu-key"})
+	if err == nil || !strings.Contains(err.Error(), `set region = "eu"`) {
+		t.Errorf("expected the key to be reported as a key of the EU region, got %v", err)
+	}
+	err = validateCredentials(&Config{APIKey: "key", Region: regionEU})
+	if err == nil || !strings.Contains(err.Error(), `set region = "us"`) {
+		t.Errorf("expected the key to be reported as a key of the US region, got %v", err)
+	}
+
+	us.Close()
+	err = validateCredentials(&Config{APIKey: "key"})
+	if err == nil || !strings.Contains(err.Error(), "skip_credentials_validation") {
+		t.Errorf("expected an unreachable Mailgun error, got %v", err)
+	}
+}
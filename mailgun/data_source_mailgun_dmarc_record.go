@@ -0,0 +1,172 @@
+package mailgun
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"strconv"
+	"strings"
+)
+
+// dmarcRecord holds the tags of a DMARC record (RFC 7489 6.3).
+type dmarcRecord struct {
+	policy          string
+	subdomainPolicy string
+	percentage      int
+	rua             []string
+	ruf             []string
+	adkim           string
+	aspf            string
+	failureOptions  string
+	reportInterval  int
+}
+
+// String renders the record, leaving out the tags which have their default value.
+func (r dmarcRecord) String() string {
+	tags := []string{"v=DMARC1", "p=" + r.policy}
+	if r.subdomainPolicy != "" {
+		tags = append(tags, "sp="+r.subdomainPolicy)
+	}
+	if r.percentage != 100 {
+		tags = append(tags, "pct="+strconv.Itoa(r.percentage))
+	}
+	if len(r.rua) > 0 {
+		tags = append(tags, "rua="+dmarcURIs(r.rua))
+	}
+	if len(r.ruf) > 0 {
+		tags = append(tags, "ruf="+dmarcURIs(r.ruf))
+	}
+	if r.adkim != "" && r.adkim != "r" {
+		tags = append(tags, "adkim="+r.adkim)
+	}
+	if r.aspf != "" && r.aspf != "r" {
+		tags = append(tags, "aspf="+r.aspf)
+	}
+	if r.failureOptions != "" && r.failureOptions != "0" {
+		tags = append(tags, "fo="+r.failureOptions)
+	}
+	if r.reportInterval != 0 && r.reportInterval != 86400 {
+		tags = append(tags, "ri="+strconv.Itoa(r.reportInterval))
+	}
+	return strings.Join(tags, "; ")
+}
+
+// dmarcURIs renders report addresses, prefixing plain email addresses with mailto:.
+func dmarcURIs(addresses []string) string {
+	uris := make([]string, len(addresses))
+	for i, a := range addresses {
+		if !strings.Contains(a, ":") {
+			a = "mailto:" + a
+		}
+		uris[i] = a
+	}
+	return strings.Join(uris, ",")
+}
+
+func dataSourceMailgunDMARCRecord() *schema.Resource {
+	dmarcPolicy := validation.StringInSlice([]string{"none", "quarantine", "reject"}, false)
+	alignment := validation.StringInSlice([]string{"r", "s"}, false)
+
+	return &schema.Resource{
+		Read: ReadDMARCRecordDataSource,
+
+		Schema: map[string]*schema.Schema{
+			"domain": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"policy": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: dmarcPolicy,
+			},
+
+			"subdomain_policy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: dmarcPolicy,
+			},
+
+			"percentage": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntBetween(0, 100),
+			},
+
+			"rua": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"ruf": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"dkim_alignment": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: alignment,
+			},
+
+			"spf_alignment": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: alignment,
+			},
+
+			"failure_options": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"report_interval": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"value": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func ReadDMARCRecordDataSource(d *schema.ResourceData, meta interface{}) error {
+	domainName, err := meta.(*Config).ResolveDomain(d.Get("domain").(string))
+	if err != nil {
+		return fmt.Errorf("Error building DMARC record: %s", err)
+	}
+
+	value := dmarcRecord{
+		policy:          d.Get("policy").(string),
+		subdomainPolicy: d.Get("subdomain_policy").(string),
+		percentage:      d.Get("percentage").(int),
+		rua:             interfaceToStringTab(d.Get("rua")),
+		ruf:             interfaceToStringTab(d.Get("ruf")),
+		adkim:           d.Get("dkim_alignment").(string),
+		aspf:            d.Get("spf_alignment").(string),
+		failureOptions:  d.Get("failure_options").(string),
+		reportInterval:  d.Get("report_interval").(int),
+	}.String()
+
+	d.SetId(strconv.Itoa(hashcode.String(domainName + " " + value)))
+	d.Set("domain", domainName)
+	d.Set("name", "_dmarc."+domainName)
+	d.Set("value", value)
+
+	return nil
+}
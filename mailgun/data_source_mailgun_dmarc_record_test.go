@@ -0,0 +1,33 @@
+package mailgun
+
+import (
+	"testing"
+)
+
+func TestDMARCRecord(t *testing.T) {
+	cases := []struct {
+		record   dmarcRecord
+		expected string
+	}{
+		{dmarcRecord{policy: "none", percentage: 100}, "v=DMARC1; p=none"},
+		{
+			dmarcRecord{
+				policy:          "quarantine",
+				subdomainPolicy: "reject",
+				percentage:      25,
+				rua:             []string{"dmarc@example.com", "https://reports.example.net/dmarc"},
+				ruf:             []string{"forensic@example.com"},
+				adkim:           "s",
+				aspf:            "r",
+				failureOptions:  "1",
+				reportInterval:  3600,
+			},
+			"v=DMARC1; p=quarantine; sp=reject; pct=25; rua=mailto:dmarc@example.com,https://reports.example.net/dmarc; ruf=mailto:forensic@example.com; adkim=s; fo=1; ri=3600",
+		},
+	}
+	for _, c := range cases {
+		if got := c.record.String(); got != c.expected {
+			t.Errorf("expected %s, got %s", c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"time"
+)
+
+func dataSourceMailgunDomainDNS() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadDomainDNSDataSource,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"origin": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ttl": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3600,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"zone_file": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"dns_records":      dnsRecordMapSchema("Data of the DNS records of the domain, keyed by purpose."),
+			"dns_record_names": dnsRecordMapSchema("Names of the DNS records of the domain, keyed by purpose."),
+			"dns_record_types": dnsRecordMapSchema("Types of the DNS records of the domain, keyed by purpose."),
+
+			"subaccount_id": subaccountIDSchema(),
+		},
+	}
+}
+
+func ReadDomainDNSDataSource(d *schema.ResourceData, meta interface{}) error {
+	domainName, err := meta.(*Config).ResolveDomain(d.Get("name").(string))
+	if err != nil {
+		return fmt.Errorf("Error reading mailgun domain DNS records: %s", err)
+	}
+
+	mg := resourceClient(d, meta, domainName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	domainResponse, err := mg.GetDomain(ctx, domainName)
+	if err != nil {
+		return newAPIError("reading", "data.mailgun_domain_dns "+domainName, err)
+	}
+
+	sending, receiving := domainResponse.SendingDNSRecords, domainResponse.ReceivingDNSRecords
+	records := mailgunDNSRecords(domainName, sending, receiving)
+
+	d.SetId(domainName)
+	d.Set("name", domainName)
+	d.Set("zone_file", zoneFileFragment(records, d.Get("origin").(string), d.Get("ttl").(int)))
+
+	values, names, types := dnsRecordMaps(dnsRecordsByPurpose(domainName, sending, receiving))
+	d.Set("dns_records", values)
+	d.Set("dns_record_names", names)
+	d.Set("dns_record_types", types)
+
+	return nil
+}
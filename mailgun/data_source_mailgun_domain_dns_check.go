@@ -0,0 +1,137 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"log"
+	"time"
+)
+
+// dnsRecordPurposes is the order in which the records of a domain are checked.
+var dnsRecordPurposes = []string{dnsRecordSPF, dnsRecordDKIM, dnsRecordMXA, dnsRecordMXB, dnsRecordTrackingCNAME}
+
+func dataSourceMailgunDomainDNSCheck() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadDomainDNSCheckDataSource,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"nameservers": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"valid": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"records": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"purpose": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"record_type": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"expected": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"found": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"status": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"message": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"subaccount_id": subaccountIDSchema(),
+		},
+	}
+}
+
+func ReadDomainDNSCheckDataSource(d *schema.ResourceData, meta interface{}) error {
+	domainName, err := meta.(*Config).ResolveDomain(d.Get("name").(string))
+	if err != nil {
+		return fmt.Errorf("Error checking mailgun domain DNS records: %s", err)
+	}
+
+	mg := resourceClient(d, meta, domainName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	domainResponse, err := mg.GetDomain(ctx, domainName)
+	if err != nil {
+		return newAPIError("reading", "data.mailgun_domain_dns_check "+domainName, err)
+	}
+
+	timeout := time.Duration(d.Get("timeout").(int)) * time.Second
+	resolver := newDNSResolver(interfaceToStringTab(d.Get("nameservers")), timeout)
+	expected := dnsRecordsByPurpose(domainName, domainResponse.SendingDNSRecords, domainResponse.ReceivingDNSRecords)
+
+	valid := true
+	var records []map[string]interface{}
+	for _, purpose := range dnsRecordPurposes {
+		record, ok := expected[purpose]
+		if !ok {
+			continue
+		}
+
+		lookupCtx, lookupCancel := context.WithTimeout(context.Background(), timeout)
+		result := checkDNSRecord(lookupCtx, resolver, record)
+		lookupCancel()
+
+		log.Printf("[DEBUG] mailgun domain %s %s record %s: %s %s", domainName, purpose, record.Name, result.Status, result.Message)
+		valid = valid && result.Status == dnsCheckValid
+		records = append(records, map[string]interface{}{
+			"purpose":     purpose,
+			"name":        record.Name,
+			"record_type": record.Type,
+			"expected":    record.data(),
+			"found":       result.Found,
+			"status":      result.Status,
+			"message":     result.Message,
+		})
+	}
+
+	d.SetId(domainName)
+	d.Set("name", domainName)
+	d.Set("valid", valid)
+	d.Set("records", records)
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"net/url"
+	"time"
+)
+
+func dataSourceMailgunDomainStats() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadDomainStatsDataSource,
+
+		Schema: statsSchema(nil),
+	}
+}
+
+func ReadDomainStatsDataSource(d *schema.ResourceData, meta interface{}) error {
+	domainName, err := meta.(*Config).ResolveDomain(d.Get("domain").(string))
+	if err != nil {
+		return fmt.Errorf("Error reading mailgun domain stats: %s", err)
+	}
+
+	mg := resourceClient(d, meta, domainName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	err = readStats(ctx, mg, "/v3/"+url.PathEscape(domainName)+"/stats/total", d)
+	if err != nil {
+		return newAPIError("reading", "data.mailgun_domain_stats "+domainName, err)
+	}
+
+	d.SetId(domainName + "/" + d.Get("duration").(string) + "/" + d.Get("resolution").(string))
+	d.Set("domain", domainName)
+
+	return nil
+}
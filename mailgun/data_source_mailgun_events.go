@@ -0,0 +1,264 @@
+package mailgun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/mailgun/mailgun-go/v3"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxEventsPageSize is the largest page the events API returns.
+const maxEventsPageSize = 300
+
+// eventItem holds the fields of an event of the events API which are common to most event types.
+type eventItem struct {
+	ID        string   `json:"id"`
+	Event     string   `json:"event"`
+	Timestamp float64  `json:"timestamp"`
+	Recipient string   `json:"recipient"`
+	Tags      []string `json:"tags"`
+	Severity  string   `json:"severity"`
+	Reason    string   `json:"reason"`
+	URL       string   `json:"url"`
+	Message   struct {
+		Headers struct {
+			MessageID string `json:"message-id"`
+			From      string `json:"from"`
+			Subject   string `json:"subject"`
+		} `json:"headers"`
+	} `json:"message"`
+	DeliveryStatus struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+		Message     string `json:"message"`
+	} `json:"delivery-status"`
+}
+
+func (e eventItem) flatten() map[string]interface{} {
+	deliveryStatusMessage := e.DeliveryStatus.Message
+	if deliveryStatusMessage == "" {
+		deliveryStatusMessage = e.DeliveryStatus.Description
+	}
+	sec, frac := splitTimestamp(e.Timestamp)
+
+	return map[string]interface{}{
+		"id":                      e.ID,
+		"event":                   e.Event,
+		"timestamp":               time.Unix(sec, frac).UTC().Format(time.RFC3339),
+		"recipient":               e.Recipient,
+		"tags":                    e.Tags,
+		"severity":                e.Severity,
+		"reason":                  e.Reason,
+		"url":                     e.URL,
+		"message_id":              e.Message.Headers.MessageID,
+		"from":                    e.Message.Headers.From,
+		"subject":                 e.Message.Headers.Subject,
+		"delivery_status_code":    e.DeliveryStatus.Code,
+		"delivery_status_message": deliveryStatusMessage,
+	}
+}
+
+func splitTimestamp(timestamp float64) (int64, int64) {
+	sec := int64(timestamp)
+	return sec, int64((timestamp - float64(sec)) * float64(time.Second))
+}
+
+func dataSourceMailgunEvents() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadEventsDataSource,
+
+		Schema: map[string]*schema.Schema{
+			"domain": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"event": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"severity": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"temporary", "permanent"}, false),
+			},
+
+			"recipient": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"tags": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"begin": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validation.ValidateRFC3339TimeString,
+				ConflictsWith: []string{"lookback"},
+			},
+
+			"end": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validation.ValidateRFC3339TimeString,
+				ConflictsWith: []string{"lookback"},
+			},
+
+			"lookback": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+			},
+
+			"limit": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntBetween(1, 10000),
+			},
+
+			"total": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"events": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id":        &schema.Schema{Type: schema.TypeString, Computed: true},
+						"event":     &schema.Schema{Type: schema.TypeString, Computed: true},
+						"timestamp": &schema.Schema{Type: schema.TypeString, Computed: true},
+						"recipient": &schema.Schema{Type: schema.TypeString, Computed: true},
+						"tags": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"severity":                &schema.Schema{Type: schema.TypeString, Computed: true},
+						"reason":                  &schema.Schema{Type: schema.TypeString, Computed: true},
+						"url":                     &schema.Schema{Type: schema.TypeString, Computed: true},
+						"message_id":              &schema.Schema{Type: schema.TypeString, Computed: true},
+						"from":                    &schema.Schema{Type: schema.TypeString, Computed: true},
+						"subject":                 &schema.Schema{Type: schema.TypeString, Computed: true},
+						"delivery_status_code":    &schema.Schema{Type: schema.TypeInt, Computed: true},
+						"delivery_status_message": &schema.Schema{Type: schema.TypeString, Computed: true},
+					},
+				},
+			},
+
+			"subaccount_id": subaccountIDSchema(),
+		},
+	}
+}
+
+func ReadEventsDataSource(d *schema.ResourceData, meta interface{}) error {
+	domainName, err := meta.(*Config).ResolveDomain(d.Get("domain").(string))
+	if err != nil {
+		return fmt.Errorf("Error listing mailgun events: %s", err)
+	}
+
+	mg := resourceClient(d, meta, domainName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*2)
+	defer cancel()
+
+	filter := make(map[string]string)
+	for param, key := range map[string]string{"event": "event", "severity": "severity", "recipient": "recipient"} {
+		if v, ok := d.GetOk(key); ok {
+			filter[param] = v.(string)
+		}
+	}
+	if tags := interfaceToStringTab(d.Get("tags")); len(tags) > 0 {
+		filter["tags"] = strings.Join(tags, " OR ")
+	}
+
+	opts := mailgun.ListEventOptions{Filter: filter}
+	if v, ok := d.GetOk("lookback"); ok {
+		lookback, _ := time.ParseDuration(v.(string))
+		opts.End = time.Now()
+		opts.Begin = opts.End.Add(-lookback)
+	}
+	for key, t := range map[string]*time.Time{"begin": &opts.Begin, "end": &opts.End} {
+		if v, ok := d.GetOk(key); ok {
+			*t, _ = time.Parse(time.RFC3339, v.(string))
+		}
+	}
+
+	limit := d.Get("limit").(int)
+	events, err := listEvents(ctx, mg, opts, limit)
+	if err != nil {
+		return newAPIError("listing", "data.mailgun_events "+domainName, err)
+	}
+
+	flattened := make([]map[string]interface{}, len(events))
+	for i, e := range events {
+		flattened[i] = e.flatten()
+	}
+
+	d.SetId(eventsDataSourceID(domainName, filter, d.Get("begin").(string), d.Get("end").(string), d.Get("lookback").(string), limit))
+	d.Set("domain", domainName)
+	d.Set("total", len(events))
+	d.Set("events", flattened)
+
+	return nil
+}
+
+// listEvents pages through the events matching opts until limit events are read.
+func listEvents(ctx context.Context, mg *mailgun.MailgunImpl, opts mailgun.ListEventOptions, limit int) ([]eventItem, error) {
+	opts.Limit = limit
+	if opts.Limit > maxEventsPageSize {
+		opts.Limit = maxEventsPageSize
+	}
+
+	var items []eventItem
+	it := mg.ListEvents(&opts)
+	var page []mailgun.Event
+	for len(items) < limit && it.Next(ctx, &page) {
+		log.Printf("[DEBUG] read a page of %d mailgun events for %s", len(it.Items), mg.Domain())
+		for _, raw := range it.Items {
+			var item eventItem
+			if err := json.Unmarshal(raw, &item); err != nil {
+				return nil, fmt.Errorf("while reading event: %s", err)
+			}
+			items = append(items, item)
+			if len(items) == limit {
+				break
+			}
+		}
+	}
+	return items, it.Err()
+}
+
+func eventsDataSourceID(domainName string, filter map[string]string, begin, end, lookback string, limit int) string {
+	parts := []string{domainName, begin, end, lookback, strconv.Itoa(limit)}
+	for k, v := range filter {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts[5:])
+	return strconv.Itoa(hashcode.String(strings.Join(parts, "\n")))
+}
+
+func validateDuration(v interface{}, k string) (ws []string, errors []error) {
+	d, err := time.ParseDuration(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a duration such as 1h or 30m: %s", k, err))
+	} else if d <= 0 {
+		errors = append(errors, fmt.Errorf("%q must be positive", k))
+	}
+	return ws, errors
+}
@@ -0,0 +1,96 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/mailgun/mailgun-go/v3"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListEvents_paging(t *testing.T) {
+	var queries []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+
+		page := r.URL.Query().Get("page")
+		var items string
+		switch page {
+		case "":
+			items = `{"id": "1", "event": "failed", "timestamp": 1571500800.5, "recipient": "bob@example.com",
+				"tags": ["newsletter"], "severity": "permanent", "reason": "bounce",
+				"message": {"headers": {"message-id": "abc@example.com", "subject": "Hello"}},
+				"delivery-status": {"code": 550, "message": "No such user"}},
+				{"id": "2", "event": "delivered", "timestamp": 1571500801}`
+		case "2":
+			items = `{"id": "3", "event": "delivered", "timestamp": 1571500802}, {"id": "4", "event": "delivered", "timestamp": 1571500803}`
+		}
+		fmt.Fprintf(w, `{"items": [%s], "paging": {"next": "%s/v3/example.com/events?page=%s"}}`, items, server.URL, "2")
+	}))
+	defer server.Close()
+
+	mg := mailgun.NewMailgun("example.com", "key")
+	mg.SetAPIBase(server.URL + "/v3")
+
+	opts := mailgun.ListEventOptions{Filter: map[string]string{"severity": "permanent"}}
+	events, err := listEvents(context.Background(), mg, opts, 3)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(events) != 3 || events[2].ID != "3" {
+		t.Fatalf("expected the 3 first events, got %+v", events)
+	}
+	if len(queries) != 2 {
+		t.Errorf("expected 2 pages to be read, got %d", len(queries))
+	}
+	if queries[0] != "limit=3&severity=permanent" {
+		t.Errorf("unexpected query %s", queries[0])
+	}
+
+	flattened := events[0].flatten()
+	expected := map[string]interface{}{
+		"event":                   "failed",
+		"timestamp":               time.Date(2019, 10, 19, 16, 0, 0, 0, time.UTC).Format(time.RFC3339),
+		"recipient":               "bob@example.com",
+		"severity":                "permanent",
+		"message_id":              "abc@example.com",
+		"subject":                 "Hello",
+		"delivery_status_code":    550,
+		"delivery_status_message": "No such user",
+	}
+	for key, value := range expected {
+		if flattened[key] != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, flattened[key])
+		}
+	}
+}
+
+func TestListEvents_lastPage(t *testing.T) {
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		w.Header().Set("Content-Type", "application/json")
+		items := ""
+		if r.URL.Query().Get("page") == "" {
+			items = `{"id": "1", "event": "delivered", "timestamp": 1571500801}`
+		}
+		fmt.Fprintf(w, `{"items": [%s], "paging": {"next": "http://%s/v3/example.com/events?page=2"}}`, items, r.Host)
+	}))
+	defer server.Close()
+
+	mg := mailgun.NewMailgun("example.com", "key")
+	mg.SetAPIBase(server.URL + "/v3")
+
+	events, err := listEvents(context.Background(), mg, mailgun.ListEventOptions{}, 100)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(events) != 1 || pages != 2 {
+		t.Errorf("expected 1 event read until an empty page, got %d events in %d pages", len(events), pages)
+	}
+}
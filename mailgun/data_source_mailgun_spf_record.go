@@ -0,0 +1,139 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func dataSourceMailgunSPFRecord() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadSPFRecordDataSource,
+
+		Schema: map[string]*schema.Schema{
+			"domain": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"mailgun_spf": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"existing": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"mechanisms": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"all": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"+all", "-all", "~all", "?all"}, false),
+			},
+
+			"value": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"fail_on_lookup_limit": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"lookup_count": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"lookup_limit_exceeded": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"subaccount_id": subaccountIDSchema(),
+		},
+	}
+}
+
+func ReadSPFRecordDataSource(d *schema.ResourceData, meta interface{}) error {
+	domainName, err := meta.(*Config).ResolveDomain(d.Get("domain").(string))
+	if err != nil {
+		return fmt.Errorf("Error building SPF record: %s", err)
+	}
+
+	mailgunSPF := d.Get("mailgun_spf").(string)
+	if mailgunSPF == "" {
+		mg := resourceClient(d, meta, domainName)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+
+		domainResponse, err := mg.GetDomain(ctx, domainName)
+		if err != nil {
+			return newAPIError("reading", "data.mailgun_spf_record "+domainName, err)
+		}
+		record, ok := dnsRecordsByPurpose(domainName, domainResponse.SendingDNSRecords, nil)[dnsRecordSPF]
+		if !ok {
+			return fmt.Errorf("No SPF record in the sending records of mailgun domain %s", domainName)
+		}
+		mailgunSPF = record.Value
+	}
+
+	existing, err := parseSPF(d.Get("existing").(string))
+	if err != nil {
+		return fmt.Errorf("Error parsing existing SPF record: %s", err)
+	}
+	mailgunRecord, err := parseSPF(mailgunSPF)
+	if err != nil {
+		return fmt.Errorf("Error parsing mailgun SPF record: %s", err)
+	}
+	extra, err := parseSPF(strings.Join(interfaceToStringTab(d.Get("mechanisms")), " "))
+	if err != nil {
+		return fmt.Errorf("Error parsing SPF mechanisms: %s", err)
+	}
+
+	merged := mergeSPF(existing, mailgunRecord, extra)
+	if all, ok := d.GetOk("all"); ok {
+		merged.all = all.(string)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	lookups := resolveSPFLookups(ctx, (&net.Resolver{}).LookupTXT, merged)
+	if lookups > spfLookupLimit && d.Get("fail_on_lookup_limit").(bool) {
+		return fmt.Errorf("Error building SPF record of %s: it needs %d DNS lookups, more than the limit of %d beyond which receivers fail "+
+			"its evaluation (RFC 7208 4.6.4). Remove mechanisms triggering lookups, or set fail_on_lookup_limit = false to build it anyway",
+			domainName, lookups, spfLookupLimit)
+	}
+	if lookups > spfLookupLimit {
+		log.Printf("[WARN] the SPF record of %s needs %d DNS lookups, more than the limit of %d: receivers will fail its evaluation",
+			domainName, lookups, spfLookupLimit)
+	}
+
+	value := merged.String()
+	d.SetId(strconv.Itoa(hashcode.String(domainName + " " + value)))
+	d.Set("domain", domainName)
+	d.Set("mailgun_spf", mailgunSPF)
+	d.Set("value", value)
+	d.Set("lookup_count", lookups)
+	d.Set("lookup_limit_exceeded", lookups > spfLookupLimit)
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package mailgun
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"strings"
+	"testing"
+)
+
+func TestReadSPFRecordDataSource_lookupLimit(t *testing.T) {
+	var mechanisms []interface{}
+	for i := 0; i <= spfLookupLimit; i++ {
+		mechanisms = append(mechanisms, fmt.Sprintf("a:host%d.example.com", i))
+	}
+
+	raw := map[string]interface{}{
+		"domain":      "example.com",
+		"mailgun_spf": "v=spf1 ~all",
+		"mechanisms":  mechanisms,
+	}
+	d := schema.TestResourceDataRaw(t, dataSourceMailgunSPFRecord().Schema, raw)
+	err := ReadSPFRecordDataSource(d, &Config{APIKey: "key"})
+	if err == nil || !strings.Contains(err.Error(), "fail_on_lookup_limit") {
+		t.Errorf("expected a record over the lookup limit to fail, got %v", err)
+	}
+
+	raw["fail_on_lookup_limit"] = false
+	d = schema.TestResourceDataRaw(t, dataSourceMailgunSPFRecord().Schema, raw)
+	if err := ReadSPFRecordDataSource(d, &Config{APIKey: "key"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Get("lookup_count") != spfLookupLimit+1 || !d.Get("lookup_limit_exceeded").(bool) {
+		t.Errorf("expected %d lookups over the limit, got %v", spfLookupLimit+1, d.Get("lookup_count"))
+	}
+}
@@ -0,0 +1,95 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func dataSourceMailgunSubaccount() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadSubaccountDataSource,
+
+		Schema: map[string]*schema.Schema{
+			"subaccount_id": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"name"},
+			},
+
+			"name": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"subaccount_id"},
+			},
+
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"status": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func ReadSubaccountDataSource(d *schema.ResourceData, meta interface{}) error {
+	mg := meta.(*Config).PrimaryClient()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	var found *subaccount
+	if id, ok := d.GetOk("subaccount_id"); ok {
+		var response subaccountResponse
+		err := apiRequest(ctx, mg, http.MethodGet, subaccountsEndpoint+"/"+url.PathEscape(id.(string)), nil, &response)
+		if err != nil {
+			return newAPIError("reading", "data.mailgun_subaccount "+id.(string), err)
+		}
+		found = &response.Subaccount
+	} else if name, ok := d.GetOk("name"); ok {
+		const limit = 100
+		for skip := 0; found == nil; skip += limit {
+			params := url.Values{}
+			params.Set("filter", name.(string))
+			params.Set("limit", strconv.Itoa(limit))
+			params.Set("skip", strconv.Itoa(skip))
+
+			var response subaccountsListResponse
+			err := apiRequest(ctx, mg, http.MethodGet, subaccountsEndpoint, params, &response)
+			if err != nil {
+				return newAPIError("listing the subaccounts for", "data.mailgun_subaccount "+name.(string), err)
+			}
+			for i, s := range response.Subaccounts {
+				if s.Name == name.(string) {
+					found = &response.Subaccounts[i]
+					break
+				}
+			}
+			if len(response.Subaccounts) < limit {
+				break
+			}
+		}
+		if found == nil {
+			return fmt.Errorf("No mailgun subaccount named %s", name)
+		}
+	} else {
+		return fmt.Errorf("One of subaccount_id or name must be set")
+	}
+
+	d.SetId(found.ID)
+	d.Set("subaccount_id", found.ID)
+	d.Set("name", found.Name)
+	d.Set("status", found.Status)
+	d.Set("enabled", found.Status != "disabled")
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"net/url"
+	"time"
+)
+
+func dataSourceMailgunTagStats() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadTagStatsDataSource,
+
+		Schema: statsSchema(map[string]*schema.Schema{
+			"tag": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		}),
+	}
+}
+
+func ReadTagStatsDataSource(d *schema.ResourceData, meta interface{}) error {
+	domainName, err := meta.(*Config).ResolveDomain(d.Get("domain").(string))
+	if err != nil {
+		return fmt.Errorf("Error reading mailgun tag stats: %s", err)
+	}
+	tag := d.Get("tag").(string)
+
+	mg := resourceClient(d, meta, domainName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	err = readStats(ctx, mg, "/v3/"+url.PathEscape(domainName)+"/tags/"+url.PathEscape(tag)+"/stats", d)
+	if err != nil {
+		return newAPIError("reading", "data.mailgun_tag_stats "+tagID(domainName, tag), err)
+	}
+
+	d.SetId(domainName + "/" + tag + "/" + d.Get("duration").(string) + "/" + d.Get("resolution").(string))
+	d.Set("domain", domainName)
+
+	return nil
+}
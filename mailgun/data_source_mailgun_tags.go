@@ -0,0 +1,145 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/mailgun/mailgun-go/v3"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func dataSourceMailgunTags() *schema.Resource {
+	return &schema.Resource{
+		Read: ReadTagsDataSource,
+
+		Schema: map[string]*schema.Schema{
+			"domain": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"prefix": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"last_seen_before": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRFC3339TimeString,
+			},
+
+			"tags": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tag": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"first_seen": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"last_seen": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"subaccount_id": subaccountIDSchema(),
+		},
+	}
+}
+
+func ReadTagsDataSource(d *schema.ResourceData, meta interface{}) error {
+	domainName, err := meta.(*Config).ResolveDomain(d.Get("domain").(string))
+	if err != nil {
+		return fmt.Errorf("Error listing mailgun tags: %s", err)
+	}
+
+	mg := resourceClient(d, meta, domainName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*2)
+	defer cancel()
+
+	var before *time.Time
+	if v, ok := d.GetOk("last_seen_before"); ok {
+		t, _ := time.Parse(time.RFC3339, v.(string))
+		before = &t
+	}
+
+	page, err := listTags(ctx, mg, domainName, d.Get("prefix").(string))
+	if err != nil {
+		return newAPIError("listing", "data.mailgun_tags "+domainName, err)
+	}
+
+	// Tags which were never seen have no last_seen and are kept, they are the stalest of all.
+	var tags []map[string]interface{}
+	for _, tag := range page {
+		if before != nil && tag.LastSeen != nil && !tag.LastSeen.Before(*before) {
+			continue
+		}
+		tags = append(tags, map[string]interface{}{
+			"tag":         tag.Value,
+			"description": tag.Description,
+			"first_seen":  formatTagTime(tag.FirstSeen),
+			"last_seen":   formatTagTime(tag.LastSeen),
+		})
+	}
+
+	d.SetId(strconv.Itoa(hashcode.String(domainName + " " + d.Get("prefix").(string) + " " + d.Get("last_seen_before").(string))))
+	d.Set("domain", domainName)
+	d.Set("tags", tags)
+
+	return nil
+}
+
+// tagsPageLimit is the number of tags listed per request, the most Mailgun accepts.
+const tagsPageLimit = 1000
+
+type tagsListResponse struct {
+	Items  []mailgun.Tag  `json:"items"`
+	Paging mailgun.Paging `json:"paging"`
+}
+
+// listTags lists the tags of domainName starting with prefix, following the next pages Mailgun links to.
+// The tag iterator of mailgun-go is not used as it stops after the first page.
+func listTags(ctx context.Context, mg *mailgun.MailgunImpl, domainName, prefix string) ([]mailgun.Tag, error) {
+	path := "/v3/" + url.PathEscape(domainName) + "/tags"
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(tagsPageLimit))
+	if prefix != "" {
+		params.Set("prefix", prefix)
+	}
+
+	var tags []mailgun.Tag
+	for {
+		var page tagsListResponse
+		if err := apiRequest(ctx, mg, http.MethodGet, path, params, &page); err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Items...)
+		if len(page.Items) < tagsPageLimit || page.Paging.Next == "" {
+			return tags, nil
+		}
+
+		next, err := url.Parse(page.Paging.Next)
+		if err != nil {
+			return nil, fmt.Errorf("invalid next page %q: %s", page.Paging.Next, err)
+		}
+		path, params = next.Path, next.Query()
+	}
+}
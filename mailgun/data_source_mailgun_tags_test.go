@@ -0,0 +1,50 @@
+package mailgun
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"testing"
+)
+
+func TestReadTagsDataSource_paging(t *testing.T) {
+	count := tagsPageLimit*2 + 42
+	tags := make([]string, count)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("campaign-%05d", i)
+	}
+	server := newFakeTagServer("example.com", append(tags, "newsletter")...)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceMailgunTags().Schema, map[string]interface{}{
+		"domain": "example.com",
+		"prefix": "campaign-",
+	})
+	if err := ReadTagsDataSource(d, &Config{APIKey: "key", apiBase: server.URL + "/v3"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if got := d.Get("tags.#"); got != count {
+		t.Fatalf("expected the %d tags of every page, got %v", count, got)
+	}
+	if got := d.Get(fmt.Sprintf("tags.%d.tag", count-1)); got != tags[count-1] {
+		t.Errorf("expected the last tag to be %s, got %v", tags[count-1], got)
+	}
+}
+
+func TestReadTagsDataSource_lastSeenBefore(t *testing.T) {
+	server := newFakeTagServer("example.com", "newsletter", "promotion")
+	defer server.Close()
+
+	for before, expected := range map[string]int{"2019-10-19T16:00:00Z": 0, "2019-10-20T00:00:00Z": 2} {
+		d := schema.TestResourceDataRaw(t, dataSourceMailgunTags().Schema, map[string]interface{}{
+			"domain":           "example.com",
+			"last_seen_before": before,
+		})
+		if err := ReadTagsDataSource(d, &Config{APIKey: "key", apiBase: server.URL + "/v3"}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got := d.Get("tags.#"); got != expected {
+			t.Errorf("last_seen_before %s: expected %d tags, got %v", before, expected, got)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package mailgun
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxLoggedBody is the number of bytes of a request or response body logged at most.
+const maxLoggedBody = 8 * 1024
+
+// redacted replaces the values of the sensitive fields in the logs.
+const redacted = "<redacted>"
+
+// sensitiveFields lists the form fields, query parameters and JSON keys whose values are never logged,
+// such as the SMTP and credential passwords or the secret of the API keys.
+var sensitiveFields = map[string]bool{
+	"password":      true,
+	"smtp_password": true,
+	"secret":        true,
+	"apikey":        true,
+	"api_key":       true,
+}
+
+// requestIDHeaders lists the response headers which may hold the ID Mailgun gives to a request.
+var requestIDHeaders = []string{"X-Mailgun-Request-Id", "X-Request-Id"}
+
+// debugLogTransport logs every request sent to the Mailgun API and its response, when TF_LOG is DEBUG or TRACE.
+// The Authorization header, hence the API key, is never logged and the sensitive fields of the bodies are redacted.
+type debugLogTransport struct {
+	next http.RoundTripper
+}
+
+func (t *debugLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		r = cloneRequest(req)
+		r.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	endpoint := redactURL(req.URL)
+	log.Printf("[DEBUG] Mailgun API request: %s %s%s", req.Method, endpoint,
+		formatLoggedBody(redactBody(req.Header.Get("Content-Type"), reqBody)))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		log.Printf("[DEBUG] Mailgun API request failed after %s: %s %s: %s", time.Since(start), req.Method, endpoint, err)
+		return resp, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	requestID := ""
+	for _, h := range requestIDHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			requestID = fmt.Sprintf(" (request id %s)", v)
+			break
+		}
+	}
+	log.Printf("[DEBUG] Mailgun API response: %s %s: %s in %s%s%s", req.Method, endpoint, resp.Status,
+		time.Since(start), requestID, formatLoggedBody(redactBody(resp.Header.Get("Content-Type"), respBody)))
+
+	return resp, nil
+}
+
+// redactURL returns u without its user info and with the sensitive query parameters redacted.
+func redactURL(u *url.URL) string {
+	c := *u
+	c.User = nil
+	if c.RawQuery != "" {
+		c.RawQuery = redactValues(c.Query()).Encode()
+	}
+	return c.String()
+}
+
+// redactBody returns body with the values of its sensitive fields redacted,
+// according to its content type: url-encoded or multipart forms, or JSON.
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "<unparsable form>"
+		}
+		return redactValues(values).Encode()
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return redactMultipart(body, params["boundary"])
+	case mediaType == "application/json" || json.Valid(body):
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return "<unparsable JSON>"
+		}
+		var out bytes.Buffer
+		encoder := json.NewEncoder(&out)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", " ")
+		if err := encoder.Encode(redactJSON(v)); err != nil {
+			return "<unparsable JSON>"
+		}
+		return strings.TrimSuffix(out.String(), "\n")
+	}
+	return string(body)
+}
+
+func redactValues(values url.Values) url.Values {
+	out := make(url.Values, len(values))
+	for k, v := range values {
+		if sensitiveFields[strings.ToLower(k)] {
+			v = []string{redacted}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// redactMultipart renders the fields of a multipart form one per line, omitting the content of the files.
+func redactMultipart(body []byte, boundary string) string {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	values := url.Values{}
+	var files []string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		if part.FileName() != "" {
+			files = append(files, fmt.Sprintf("%s=<file %s>", part.FormName(), part.FileName()))
+			continue
+		}
+		v, err := ioutil.ReadAll(part)
+		if err != nil {
+			return "<unparsable multipart form>"
+		}
+		values.Add(part.FormName(), string(v))
+	}
+
+	values = redactValues(values)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var lines []string
+	for _, k := range keys {
+		for _, v := range values[k] {
+			lines = append(lines, k+"="+v)
+		}
+	}
+	return strings.Join(append(lines, files...), "\n")
+}
+
+// redactJSON redacts the string values of the sensitive keys of a decoded JSON document, at any depth.
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if _, ok := e.(string); ok && sensitiveFields[strings.ToLower(k)] {
+				v[k] = redacted
+			} else {
+				v[k] = redactJSON(e)
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactJSON(e)
+		}
+	}
+	return v
+}
+
+func formatLoggedBody(body string) string {
+	if body == "" {
+		return ""
+	}
+	if len(body) > maxLoggedBody {
+		body = fmt.Sprintf("%s\n... (%d bytes truncated)", body[:maxLoggedBody], len(body)-maxLoggedBody)
+	}
+	return "\n" + body
+}
@@ -0,0 +1,77 @@
+package mailgun
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"github.com/mailgun/mailgun-go/v3"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDebugLogTransport(t *testing.T) {
+	defer os.Setenv("TF_LOG", os.Getenv("TF_LOG"))
+	os.Setenv("TF_LOG", "DEBUG")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	var password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		password = r.FormValue("smtp_password")
+		w.Header().Set("X-Mailgun-Request-Id", "request-42")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message": "Domain has been created", "domain": {"name": "domain.com"}, "key": {"secret": "key-secret"}}`))
+	}))
+	defer server.Close()
+
+	config := &Config{APIKey: "api-key-value", apiBase: server.URL + "/v3"}
+	mg := config.Client("domain.com", "")
+	_, err := mg.CreateDomain(context.Background(), "domain.com", &mailgun.CreateDomainOptions{Password: "smtp-secret"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if password != "smtp-secret" {
+		t.Fatalf("expected the request body to be sent untouched, got smtp_password %q", password)
+	}
+
+	logged := buf.String()
+	for _, expected := range []string{"POST " + server.URL + "/v3/domains", "200 OK", "request-42", "domain.com", "Domain has been created"} {
+		if !strings.Contains(logged, expected) {
+			t.Errorf("expected the logs to contain %q:\n%s", expected, logged)
+		}
+	}
+	secrets := []string{"api-key-value", base64.StdEncoding.EncodeToString([]byte("api:api-key-value")), "smtp-secret", "key-secret"}
+	for _, secret := range secrets {
+		if strings.Contains(logged, secret) {
+			t.Errorf("expected the logs not to contain %q:\n%s", secret, logged)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	cases := []struct {
+		contentType string
+		body        string
+		expected    string
+	}{
+		{"application/x-www-form-urlencoded", "login=alice&password=secret", "login=alice&password=%3Credacted%3E"},
+		{"multipart/form-data; boundary=b", "--b\r\nContent-Disposition: form-data; name=\"login\"\r\n\r\nalice\r\n" +
+			"--b\r\nContent-Disposition: form-data; name=\"password\"\r\n\r\nsecret\r\n--b--\r\n", "login=alice\npassword=<redacted>"},
+		{"application/json", `{"items": [{"login": "alice", "Password": "secret"}]}`,
+			"{\n \"items\": [\n  {\n   \"Password\": \"<redacted>\",\n   \"login\": \"alice\"\n  }\n ]\n}"},
+		{"text/plain", "Forbidden", "Forbidden"},
+		{"", "", ""},
+	}
+
+	for _, c := range cases {
+		if got := redactBody(c.contentType, []byte(c.body)); got != c.expected {
+			t.Errorf("redactBody(%q, %q): expected %q, got %q", c.contentType, c.body, c.expected, got)
+		}
+	}
+}
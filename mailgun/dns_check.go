@@ -0,0 +1,164 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of a checked DNS record.
+const (
+	dnsCheckValid    = "valid"
+	dnsCheckMissing  = "missing"
+	dnsCheckMismatch = "mismatch"
+	dnsCheckError    = "error"
+)
+
+// dnsCheckResult is the outcome of resolving a record Mailgun expects.
+type dnsCheckResult struct {
+	Status  string
+	Found   []string
+	Message string
+}
+
+// newDNSResolver returns a resolver querying nameservers in turn, or the system resolver
+// when none is given. Nameservers are addresses with an optional port, which defaults to 53.
+func newDNSResolver(nameservers []string, timeout time.Duration) *net.Resolver {
+	if len(nameservers) == 0 {
+		return &net.Resolver{}
+	}
+
+	addresses := make([]string, len(nameservers))
+	for i, ns := range nameservers {
+		if _, _, err := net.SplitHostPort(ns); err != nil {
+			ns = net.JoinHostPort(strings.Trim(ns, "[]"), "53")
+		}
+		addresses[i] = ns
+	}
+
+	var next uint32
+	dialer := &net.Dialer{Timeout: timeout}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			i := atomic.AddUint32(&next, 1) - 1
+			return dialer.DialContext(ctx, network, addresses[int(i)%len(addresses)])
+		},
+	}
+}
+
+// checkDNSRecord resolves the record Mailgun expects and compares it with what is published.
+// A published SPF record is valid when it includes all the domains of the expected one, so that
+// it can be merged with the SPF records of other senders. TXT records split in several strings
+// are compared once concatenated.
+func checkDNSRecord(ctx context.Context, resolver *net.Resolver, expected dnsRecord) dnsCheckResult {
+	name := strings.TrimSuffix(expected.Name, ".") + "."
+
+	switch expected.Type {
+	case "TXT":
+		txts, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return dnsLookupFailure(err)
+		}
+		if strings.HasPrefix(expected.Value, "v=spf1") {
+			return checkSPF(txts, expected.Value)
+		}
+		for _, txt := range txts {
+			if stripSpaces(txt) == stripSpaces(expected.Value) {
+				return dnsCheckResult{Status: dnsCheckValid, Found: txts}
+			}
+		}
+		return dnsCheckResult{Status: dnsCheckMismatch, Found: txts, Message: "no TXT record matches the expected value"}
+
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return dnsLookupFailure(err)
+		}
+		found := make([]string, len(mxs))
+		result := dnsCheckResult{Status: dnsCheckMismatch, Message: fmt.Sprintf("%s is not an exchange of the domain", expected.Value)}
+		for i, mx := range mxs {
+			found[i] = fmt.Sprintf("%d %s", mx.Pref, mx.Host)
+			if sameDNSName(mx.Host, expected.Value) {
+				result = dnsCheckResult{Status: dnsCheckValid}
+			}
+		}
+		result.Found = found
+		return result
+
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return dnsLookupFailure(err)
+		}
+		if sameDNSName(cname, name) {
+			return dnsCheckResult{Status: dnsCheckMissing, Message: "no CNAME record"}
+		}
+		if !sameDNSName(cname, expected.Value) {
+			return dnsCheckResult{Status: dnsCheckMismatch, Found: []string{cname}, Message: fmt.Sprintf("expected an alias of %s", expected.Value)}
+		}
+		return dnsCheckResult{Status: dnsCheckValid, Found: []string{cname}}
+	}
+
+	return dnsCheckResult{Status: dnsCheckError, Message: fmt.Sprintf("cannot check %s records", expected.Type)}
+}
+
+func checkSPF(txts []string, expected string) dnsCheckResult {
+	var spfs []string
+	for _, txt := range txts {
+		if strings.HasPrefix(txt, "v=spf1 ") || txt == "v=spf1" {
+			spfs = append(spfs, txt)
+		}
+	}
+
+	if len(spfs) == 0 {
+		return dnsCheckResult{Status: dnsCheckMissing, Found: txts, Message: "no SPF record"}
+	}
+	if len(spfs) > 1 {
+		return dnsCheckResult{Status: dnsCheckMismatch, Found: spfs, Message: "several SPF records are published, only one is allowed"}
+	}
+
+	published := spfIncludes(spfs[0])
+	var missing []string
+	for include := range spfIncludes(expected) {
+		if !published[include] {
+			missing = append(missing, include)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return dnsCheckResult{Status: dnsCheckMismatch, Found: spfs, Message: "the SPF record does not include " + strings.Join(missing, ", ")}
+	}
+	return dnsCheckResult{Status: dnsCheckValid, Found: spfs}
+}
+
+// spfIncludes returns the domains included by an SPF record.
+func spfIncludes(spf string) map[string]bool {
+	includes := make(map[string]bool)
+	for _, term := range strings.Fields(spf) {
+		term = strings.TrimLeft(strings.ToLower(term), "+")
+		if strings.HasPrefix(term, "include:") {
+			includes[strings.TrimSuffix(strings.TrimPrefix(term, "include:"), ".")] = true
+		}
+	}
+	return includes
+}
+
+func dnsLookupFailure(err error) dnsCheckResult {
+	if dnsErr, ok := err.(*net.DNSError); ok && !dnsErr.IsTimeout && !dnsErr.Temporary() {
+		return dnsCheckResult{Status: dnsCheckMissing, Message: dnsErr.Error()}
+	}
+	return dnsCheckResult{Status: dnsCheckError, Message: err.Error()}
+}
+
+func sameDNSName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
+
+func stripSpaces(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
@@ -0,0 +1,180 @@
+package mailgun
+
+import (
+	"context"
+	"golang.org/x/net/dns/dnsmessage"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDNSZone maps a fully qualified name to its resource records.
+type testDNSZone map[string][]dnsmessage.Resource
+
+// startTestDNSServer serves zone over UDP on a local port and returns its address,
+// and a function stopping the server.
+func startTestDNSServer(t *testing.T, zone testDNSZone) (string, func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if response, err := answerTestDNSQuery(zone, buf[:n]); err == nil {
+				conn.WriteTo(response, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String(), func() { conn.Close() }
+}
+
+func answerTestDNSQuery(zone testDNSZone, query []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	question, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(question.Name.String())
+	records, known := zone[name]
+	header.Response = true
+	header.Authoritative = true
+	if !known {
+		header.RCode = dnsmessage.RCodeNameError
+	}
+
+	b := dnsmessage.NewBuilder(nil, header)
+	b.StartQuestions()
+	b.Question(question)
+	b.StartAnswers()
+	for _, r := range records {
+		if r.Header.Type != question.Type && r.Header.Type != dnsmessage.TypeCNAME {
+			continue
+		}
+		r.Header.Name = question.Name
+		r.Header.Class = dnsmessage.ClassINET
+		r.Header.TTL = 300
+		switch body := r.Body.(type) {
+		case *dnsmessage.TXTResource:
+			err = b.TXTResource(r.Header, *body)
+		case *dnsmessage.MXResource:
+			err = b.MXResource(r.Header, *body)
+		case *dnsmessage.CNAMEResource:
+			err = b.CNAMEResource(r.Header, *body)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.Finish()
+}
+
+func txtRecord(strs ...string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Type: dnsmessage.TypeTXT},
+		Body:   &dnsmessage.TXTResource{TXT: strs},
+	}
+}
+
+func mxRecord(pref uint16, host string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Type: dnsmessage.TypeMX},
+		Body:   &dnsmessage.MXResource{Pref: pref, MX: dnsmessage.MustNewName(host)},
+	}
+}
+
+func cnameRecord(target string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Type: dnsmessage.TypeCNAME},
+		Body:   &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(target)},
+	}
+}
+
+func TestCheckDNSRecord(t *testing.T) {
+	dkim := "k=rsa; p=" + strings.Repeat("A", 300)
+
+	nameserver, stop := startTestDNSServer(t, testDNSZone{
+		"example.com.": {
+			txtRecord("google-site-verification=abc"),
+			txtRecord("v=spf1 include:_spf.google.com include:mailgun.org ~all"),
+			mxRecord(10, "mxa.mailgun.org."),
+			mxRecord(20, "mxb.mailgun.org."),
+		},
+		"smtp._domainkey.example.com.":  {txtRecord(dkim[:255], dkim[255:])},
+		"email.example.com.":            {cnameRecord("mailgun.org.")},
+		"nospf.example.com.":            {txtRecord("v=spf1 include:_spf.google.com ~all")},
+		"twospf.example.com.":           {txtRecord("v=spf1 include:mailgun.org ~all"), txtRecord("v=spf1 -all")},
+		"stale._domainkey.example.com.": {txtRecord("k=rsa; p=OLD")},
+		"email.other.com.":              {cnameRecord("tracking.example.net.")},
+		"other.com.":                    {mxRecord(10, "mx.example.net.")},
+	})
+	defer stop()
+	resolver := newDNSResolver([]string{nameserver}, 2*time.Second)
+
+	cases := []struct {
+		record  dnsRecord
+		status  string
+		message string
+	}{
+		{dnsRecord{Name: "example.com", Type: "TXT", Value: "v=spf1 include:mailgun.org ~all"}, dnsCheckValid, ""},
+		{dnsRecord{Name: "smtp._domainkey.example.com", Type: "TXT", Value: dkim}, dnsCheckValid, ""},
+		{dnsRecord{Name: "example.com", Type: "MX", Priority: "10", Value: "mxa.mailgun.org"}, dnsCheckValid, ""},
+		{dnsRecord{Name: "example.com", Type: "MX", Priority: "10", Value: "mxb.mailgun.org"}, dnsCheckValid, ""},
+		{dnsRecord{Name: "email.example.com", Type: "CNAME", Value: "mailgun.org"}, dnsCheckValid, ""},
+		{dnsRecord{Name: "nospf.example.com", Type: "TXT", Value: "v=spf1 include:mailgun.org ~all"}, dnsCheckMismatch, "does not include mailgun.org"},
+		{dnsRecord{Name: "twospf.example.com", Type: "TXT", Value: "v=spf1 include:mailgun.org ~all"}, dnsCheckMismatch, "several SPF records"},
+		{dnsRecord{Name: "stale._domainkey.example.com", Type: "TXT", Value: dkim}, dnsCheckMismatch, "no TXT record matches"},
+		{dnsRecord{Name: "email.other.com", Type: "CNAME", Value: "mailgun.org"}, dnsCheckMismatch, "expected an alias of mailgun.org"},
+		{dnsRecord{Name: "other.com", Type: "MX", Priority: "10", Value: "mxa.mailgun.org"}, dnsCheckMismatch, "is not an exchange"},
+		{dnsRecord{Name: "missing.example.com", Type: "TXT", Value: "v=spf1 include:mailgun.org ~all"}, dnsCheckMissing, ""},
+		{dnsRecord{Name: "missing._domainkey.example.com", Type: "TXT", Value: dkim}, dnsCheckMissing, ""},
+	}
+
+	for _, c := range cases {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		result := checkDNSRecord(ctx, resolver, c.record)
+		cancel()
+
+		if result.Status != c.status {
+			t.Errorf("%s %s: expected status %s, got %+v", c.record.Type, c.record.Name, c.status, result)
+		}
+		if !strings.Contains(result.Message, c.message) {
+			t.Errorf("%s %s: expected message containing %q, got %q", c.record.Type, c.record.Name, c.message, result.Message)
+		}
+	}
+}
+
+func TestNewDNSResolver_unreachableNameserver(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer conn.Close()
+
+	resolver := newDNSResolver([]string{conn.LocalAddr().String()}, time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	result := checkDNSRecord(ctx, resolver, dnsRecord{Name: "example.com", Type: "MX", Value: "mxa.mailgun.org"})
+	if result.Status != dnsCheckError {
+		t.Errorf("expected a timeout to be reported as an error, got %+v", result)
+	}
+}
+
+func TestSPFIncludes(t *testing.T) {
+	includes := spfIncludes("v=spf1 include:_spf.google.com +include:MAILGUN.org. a mx ~all")
+	if len(includes) != 2 || !includes["_spf.google.com"] || !includes["mailgun.org"] {
+		t.Errorf("unexpected includes %v", includes)
+	}
+}
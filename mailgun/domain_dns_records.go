@@ -0,0 +1,219 @@
+package mailgun
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/mailgun/mailgun-go/v3"
+	"sort"
+	"strings"
+)
+
+// Purposes of the DNS records of a domain, used as the keys of the dns_records maps.
+const (
+	dnsRecordSPF           = "spf"
+	dnsRecordDKIM          = "dkim"
+	dnsRecordMXA           = "mx_a"
+	dnsRecordMXB           = "mx_b"
+	dnsRecordTrackingCNAME = "tracking_cname"
+)
+
+// dnsRecord is a DNS record Mailgun expects for a domain.
+type dnsRecord struct {
+	Name     string
+	Type     string
+	Priority string
+	Value    string
+}
+
+// data returns the record data as written in a zone, e.g. "10 mxa.mailgun.org" for a MX record.
+func (r dnsRecord) data() string {
+	if r.Priority != "" {
+		return r.Priority + " " + r.Value
+	}
+	return r.Value
+}
+
+// mailgunDNSZone returns the zone of the Mailgun hosts referenced by the DNS records
+// of the domains of the region of the provider.
+func mailgunDNSZone(config *Config) string {
+	if config.RegionName() == regionEU {
+		return "eu.mailgun.org"
+	}
+	return "mailgun.org"
+}
+
+// predictableDNSRecords returns the records of records which are known before the domain is created,
+// that is all of them but the DKIM record.
+func predictableDNSRecords(records map[string]dnsRecord) map[string]dnsRecord {
+	predictable := make(map[string]dnsRecord, len(records))
+	for key, r := range records {
+		if key != dnsRecordDKIM {
+			predictable[key] = r
+		}
+	}
+	return predictable
+}
+
+// predictDNSRecords returns the records Mailgun asks to publish for a new domain.
+// The DKIM record is missing as its selector and key are only chosen when the domain is created.
+func predictDNSRecords(domain, zone string) map[string]dnsRecord {
+	return map[string]dnsRecord{
+		dnsRecordSPF:           {Name: domain, Type: "TXT", Value: "v=spf1 include:" + zone + " ~all"},
+		dnsRecordMXA:           {Name: domain, Type: "MX", Priority: "10", Value: "mxa." + zone},
+		dnsRecordMXB:           {Name: domain, Type: "MX", Priority: "10", Value: "mxb." + zone},
+		dnsRecordTrackingCNAME: {Name: "email." + domain, Type: "CNAME", Value: zone},
+	}
+}
+
+// dnsRecordsByPurpose classifies the sending and receiving records returned by Mailgun for domain.
+// Records which serve none of the known purposes are left out.
+func dnsRecordsByPurpose(domain string, sending, receiving []mailgun.DNSRecord) map[string]dnsRecord {
+	records := make(map[string]dnsRecord)
+
+	for _, r := range sending {
+		record := dnsRecord{Name: r.Name, Type: strings.ToUpper(r.RecordType), Value: r.Value}
+		switch {
+		case record.Type == "TXT" && strings.HasPrefix(r.Value, "v=spf1"):
+			records[dnsRecordSPF] = record
+		case record.Type == "TXT" && strings.Contains(r.Name, "._domainkey."):
+			records[dnsRecordDKIM] = record
+		case record.Type == "CNAME":
+			records[dnsRecordTrackingCNAME] = record
+		}
+	}
+
+	var mx []dnsRecord
+	for _, r := range mailgunDNSRecords(domain, nil, receiving) {
+		if r.Type == "MX" {
+			mx = append(mx, r)
+		}
+	}
+	sort.Slice(mx, func(i, j int) bool { return mx[i].Value < mx[j].Value })
+	for i, key := range []string{dnsRecordMXA, dnsRecordMXB} {
+		if i < len(mx) {
+			records[key] = mx[i]
+		}
+	}
+
+	return records
+}
+
+// dnsRecordMaps returns the dns_records, dns_record_names and dns_record_types attributes of records.
+func dnsRecordMaps(records map[string]dnsRecord) (values, names, types map[string]interface{}) {
+	values = make(map[string]interface{}, len(records))
+	names = make(map[string]interface{}, len(records))
+	types = make(map[string]interface{}, len(records))
+	for key, r := range records {
+		values[key] = r.data()
+		names[key] = r.Name
+		types[key] = r.Type
+	}
+	return values, names, types
+}
+
+// customizeDomainDNSRecordsDiff predicts the DNS records of a new domain so that resources
+// publishing them can be planned in the same run as the domain. The DKIM record cannot be predicted:
+// the dns_records maps are known after apply, and the predictable_dns_records maps hold the others.
+func customizeDomainDNSRecordsDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && !d.HasChange("name") {
+		return nil
+	}
+
+	keys := []string{"dns_records", "dns_record_names", "dns_record_types"}
+	predictableKeys := []string{"predictable_dns_records", "predictable_dns_record_names", "predictable_dns_record_types"}
+	if d.NewValueKnown("name") {
+		records := predictDNSRecords(d.Get("name").(string), mailgunDNSZone(meta.(*Config)))
+		values, names, types := dnsRecordMaps(records)
+		for i, value := range []map[string]interface{}{values, names, types} {
+			if err := d.SetNew(predictableKeys[i], value); err != nil {
+				return fmt.Errorf("Error predicting the DNS records of %s: %s", d.Get("name").(string), err)
+			}
+		}
+	} else {
+		keys = append(keys, predictableKeys...)
+	}
+
+	for _, key := range keys {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dnsRecordMapSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: description,
+	}
+}
+
+// maxTXTStringLength is the maximum length of a single character-string of a TXT record (RFC 1035 3.3).
+const maxTXTStringLength = 255
+
+// mailgunDNSRecords converts the sending and receiving records returned by Mailgun for domain.
+// Mailgun does not name the receiving records: they belong to domain.
+func mailgunDNSRecords(domain string, sending, receiving []mailgun.DNSRecord) []dnsRecord {
+	records := make([]dnsRecord, 0, len(sending)+len(receiving))
+	for _, r := range append(append([]mailgun.DNSRecord{}, sending...), receiving...) {
+		record := dnsRecord{Name: r.Name, Type: strings.ToUpper(r.RecordType), Value: r.Value}
+		if record.Name == "" {
+			record.Name = domain
+		}
+		if record.Type == "MX" {
+			record.Priority = r.Priority
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// zoneFileFragment renders records as RFC 1035 resource records.
+// Names are relative to origin when it is set, and fully qualified otherwise.
+func zoneFileFragment(records []dnsRecord, origin string, ttl int) string {
+	var b strings.Builder
+	for _, r := range records {
+		var data string
+		switch r.Type {
+		case "TXT":
+			data = quoteTXT(r.Value)
+		case "MX", "CNAME":
+			data = r.Priority + " " + zoneFileName(r.Value, "")
+		default:
+			data = r.data()
+		}
+
+		fmt.Fprintf(&b, "%s\t%d\tIN\t%s\t%s\n", zoneFileName(r.Name, origin), ttl, r.Type, strings.TrimSpace(data))
+	}
+	return b.String()
+}
+
+// zoneFileName returns name relative to origin, or fully qualified when it is outside of origin.
+func zoneFileName(name, origin string) string {
+	name = strings.TrimSuffix(name, ".")
+	origin = strings.TrimSuffix(origin, ".")
+	switch {
+	case origin == "":
+	case strings.EqualFold(name, origin):
+		return "@"
+	case strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(origin)):
+		return name[:len(name)-len(origin)-1]
+	}
+	return name + "."
+}
+
+var txtEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// quoteTXT renders value as the quoted character-strings of a TXT record,
+// split in strings of at most 255 bytes as long DKIM keys do not fit in a single one.
+func quoteTXT(value string) string {
+	var parts []string
+	for len(value) > maxTXTStringLength {
+		parts = append(parts, `"`+txtEscaper.Replace(value[:maxTXTStringLength])+`"`)
+		value = value[maxTXTStringLength:]
+	}
+	parts = append(parts, `"`+txtEscaper.Replace(value)+`"`)
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,146 @@
+package mailgun
+
+import (
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mailgun/mailgun-go/v3"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDNSRecordsByPurpose(t *testing.T) {
+	sending := []mailgun.DNSRecord{
+		{RecordType: "TXT", Name: "example.com", Value: "v=spf1 include:mailgun.org ~all"},
+		{RecordType: "TXT", Name: "smtp._domainkey.example.com", Value: "k=rsa; p=MIGf"},
+		{RecordType: "CNAME", Name: "email.example.com", Value: "mailgun.org"},
+	}
+	receiving := []mailgun.DNSRecord{
+		{RecordType: "MX", Priority: "10", Value: "mxb.mailgun.org"},
+		{RecordType: "MX", Priority: "10", Value: "mxa.mailgun.org"},
+	}
+
+	records := dnsRecordsByPurpose("example.com", sending, receiving)
+
+	values, names, types := dnsRecordMaps(records)
+	expectedValues := map[string]interface{}{
+		"spf":            "v=spf1 include:mailgun.org ~all",
+		"dkim":           "k=rsa; p=MIGf",
+		"mx_a":           "10 mxa.mailgun.org",
+		"mx_b":           "10 mxb.mailgun.org",
+		"tracking_cname": "mailgun.org",
+	}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("expected values %v, got %v", expectedValues, values)
+	}
+	if names["dkim"] != "smtp._domainkey.example.com" || names["tracking_cname"] != "email.example.com" || names["mx_a"] != "example.com" {
+		t.Errorf("unexpected names %v", names)
+	}
+	if types["spf"] != "TXT" || types["mx_a"] != "MX" || types["tracking_cname"] != "CNAME" {
+		t.Errorf("unexpected types %v", types)
+	}
+
+	// The prediction must match what Mailgun returns, except for the DKIM record.
+	predicted := predictDNSRecords("example.com", "mailgun.org")
+	delete(records, dnsRecordDKIM)
+	for key, r := range records {
+		if predicted[key] != r {
+			t.Errorf("predicted %s record %+v, Mailgun returns %+v", key, predicted[key], r)
+		}
+	}
+}
+
+func TestCustomizeDomainDNSRecordsDiff(t *testing.T) {
+	for region, zone := range map[string]string{"us": "mailgun.org", "eu": "eu.mailgun.org"} {
+		provider := Provider().(*schema.Provider)
+		providerConfig, err := config.NewRawConfig(map[string]interface{}{
+			"apikey":                      "key",
+			"region":                      region,
+			"skip_credentials_validation": true,
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := provider.Configure(terraform.NewResourceConfig(providerConfig)); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		rawConfig, err := config.NewRawConfig(map[string]interface{}{"name": "example.com"})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		diff, err := provider.ResourcesMap["mailgun_domain"].Diff(nil, terraform.NewResourceConfig(rawConfig), provider.Meta())
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		expected := map[string]string{
+			"predictable_dns_records.spf":                 "v=spf1 include:" + zone + " ~all",
+			"predictable_dns_records.mx_a":                "10 mxa." + zone,
+			"predictable_dns_record_names.tracking_cname": "email.example.com",
+			"predictable_dns_record_types.mx_b":           "MX",
+		}
+		for key, value := range expected {
+			attr, ok := diff.Attributes[key]
+			if !ok {
+				t.Errorf("%s: no diff for %s", region, key)
+				continue
+			}
+			if attr.NewComputed || attr.New != value {
+				t.Errorf("%s: expected %s to be %s, got %+v", region, key, value, attr)
+			}
+		}
+		if _, ok := diff.Attributes["predictable_dns_records.dkim"]; ok {
+			t.Error("the dkim record cannot be known before the domain is created")
+		}
+		for _, key := range []string{"dns_records", "dns_record_names", "dns_record_types"} {
+			if attr, ok := diff.Attributes[key+".%"]; !ok || !attr.NewComputed {
+				t.Errorf("%s: expected %s to be known after apply, got %+v", region, key, attr)
+			}
+		}
+	}
+}
+
+func TestZoneFileFragment(t *testing.T) {
+	dkim := "k=rsa; p=" + strings.Repeat("A", 300)
+	records := mailgunDNSRecords("mail.example.com", []mailgun.DNSRecord{
+		{RecordType: "TXT", Name: "mail.example.com", Value: "v=spf1 include:mailgun.org ~all"},
+		{RecordType: "TXT", Name: "smtp._domainkey.mail.example.com", Value: dkim},
+		{RecordType: "CNAME", Name: "email.mail.example.com", Value: "mailgun.org"},
+	}, []mailgun.DNSRecord{
+		{RecordType: "MX", Priority: "10", Value: "mxa.mailgun.org"},
+	})
+
+	expected := "mail.example.com.\t300\tIN\tTXT\t\"v=spf1 include:mailgun.org ~all\"\n" +
+		"smtp._domainkey.mail.example.com.\t300\tIN\tTXT\t\"" + dkim[:255] + "\" \"" + dkim[255:] + "\"\n" +
+		"email.mail.example.com.\t300\tIN\tCNAME\tmailgun.org.\n" +
+		"mail.example.com.\t300\tIN\tMX\t10 mxa.mailgun.org.\n"
+	if got := zoneFileFragment(records, "", 300); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	relative := zoneFileFragment(records, "example.com.", 300)
+	for _, line := range []string{"mail\t300\tIN\tTXT\t", "smtp._domainkey.mail\t300", "mail\t300\tIN\tMX\t10 mxa.mailgun.org.\n"} {
+		if !strings.Contains(relative, line) {
+			t.Errorf("expected %q in:\n%s", line, relative)
+		}
+	}
+	if apex := zoneFileFragment(records[:1], "mail.example.com", 300); !strings.HasPrefix(apex, "@\t") {
+		t.Errorf("expected the record of the origin to be named @, got %s", apex)
+	}
+}
+
+func TestQuoteTXT(t *testing.T) {
+	cases := map[string]string{
+		"":                       `""`,
+		`v=spf1 include:"x" \ y`: `"v=spf1 include:\"x\" \\ y"`,
+		strings.Repeat("a", 255): `"` + strings.Repeat("a", 255) + `"`,
+		strings.Repeat("a", 256): `"` + strings.Repeat("a", 255) + `" "a"`,
+	}
+	for value, expected := range cases {
+		if got := quoteTXT(value); got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+}
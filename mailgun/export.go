@@ -0,0 +1,383 @@
+package mailgun
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/mailgun/mailgun-go/v3"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ExportOptions configures the export of a Mailgun account to Terraform configuration.
+type ExportOptions struct {
+	// Dir is the directory the configuration files are written to.
+	Dir string
+	// Domains restricts the export to these domains. All the domains are exported when empty.
+	Domains []string
+	// ImportBlocks writes import blocks, supported by Terraform 1.5 and later,
+	// instead of a script of terraform import commands.
+	ImportBlocks bool
+}
+
+// exportedDomain holds what the mailgun_domain resource reads of a domain.
+type exportedDomain struct {
+	Domain             mailgun.Domain
+	DKIMKeySize        int
+	ForceDKIMAuthority bool
+	Connection         mailgun.DomainConnection
+	Tracking           mailgun.DomainTracking
+	Logins             []string
+	Webhooks           map[string][]string
+}
+
+// exportedAccount holds the resources of an account to export.
+type exportedAccount struct {
+	Domains []exportedDomain
+	Routes  []mailgun.Route
+}
+
+// Export reads the domains, routes and webhooks of the account of config and writes the
+// Terraform configuration managing them to opts.Dir, along with the imports of the resources.
+// Webhooks are not managed by the provider, they are only written as comments.
+func Export(config *Config, opts ExportOptions) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+
+	account, err := readAccount(ctx, config, opts.Domains)
+	if err != nil {
+		return err
+	}
+
+	names := newExportNames(account)
+	files := []struct {
+		name   string
+		mode   os.FileMode
+		render func(*bytes.Buffer)
+	}{
+		{"domains.tf", 0644, func(buf *bytes.Buffer) { renderDomains(buf, account, names) }},
+		{"routes.tf", 0644, func(buf *bytes.Buffer) { renderRoutes(buf, account, names) }},
+		{"import.sh", 0755, func(buf *bytes.Buffer) { renderImports(buf, account, names, false) }},
+	}
+	if opts.ImportBlocks {
+		files[2].name, files[2].mode = "imports.tf", 0644
+		files[2].render = func(buf *bytes.Buffer) { renderImports(buf, account, names, true) }
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return err
+	}
+	for _, file := range files {
+		var buf bytes.Buffer
+		file.render(&buf)
+		path := filepath.Join(opts.Dir, file.name)
+		if err := ioutil.WriteFile(path, buf.Bytes(), file.mode); err != nil {
+			return err
+		}
+		log.Printf("[INFO] wrote %s", path)
+	}
+	return nil
+}
+
+func readAccount(ctx context.Context, config *Config, only []string) (exportedAccount, error) {
+	var account exportedAccount
+	mg := config.Client("", "")
+
+	wanted := make(map[string]bool)
+	for _, name := range only {
+		wanted[name] = true
+	}
+
+	var names []string
+	it := mg.ListDomains(nil)
+	var page []mailgun.Domain
+	for it.Next(ctx, &page) {
+		for _, domain := range page {
+			if len(wanted) == 0 || wanted[domain.Name] {
+				names = append(names, domain.Name)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return account, newAPIError("listing", "the mailgun domains", err)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		log.Printf("[INFO] reading mailgun domain %s", name)
+		domain, err := readExportedDomain(ctx, config.Client(name, ""), name)
+		if err != nil {
+			return account, err
+		}
+		account.Domains = append(account.Domains, domain)
+	}
+
+	routes := mg.ListRoutes(nil)
+	var routesPage []mailgun.Route
+	for routes.Next(ctx, &routesPage) {
+		account.Routes = append(account.Routes, routesPage...)
+	}
+	if err := routes.Err(); err != nil {
+		return account, newAPIError("listing", "the mailgun routes", err)
+	}
+	sort.SliceStable(account.Routes, func(i, j int) bool {
+		return account.Routes[i].Priority < account.Routes[j].Priority
+	})
+
+	return account, nil
+}
+
+func readExportedDomain(ctx context.Context, mg *mailgun.MailgunImpl, name string) (exportedDomain, error) {
+	var domain exportedDomain
+
+	response, err := mg.GetDomain(ctx, name)
+	if err != nil {
+		return domain, newAPIError("reading", "mailgun_domain "+name, err)
+	}
+	domain.Domain = response.Domain
+
+	domain.DKIMKeySize = 1024
+	if record := findDkimRecord(response.SendingDNSRecords); record != nil {
+		if size, err := dkimKeySizeFromRecord(record.Value); err == nil {
+			domain.DKIMKeySize = size
+		}
+	}
+	domain.ForceDKIMAuthority, err = isDkimAuthorityForced(ctx, mg, name, response.SendingDNSRecords)
+	if err != nil {
+		return domain, newAPIError("reading the DKIM authority of", "mailgun_domain "+name, err)
+	}
+
+	domain.Connection, err = mg.GetDomainConnection(ctx, name)
+	if err != nil {
+		return domain, newAPIError("reading the connection settings of", "mailgun_domain "+name, err)
+	}
+
+	domain.Tracking, err = mg.GetDomainTracking(ctx, name)
+	if err != nil {
+		return domain, newAPIError("reading the tracking settings of", "mailgun_domain "+name, err)
+	}
+
+	credentials, err := ListCredentials(mg)
+	if err != nil {
+		return domain, newAPIError("reading the credentials of", "mailgun_domain "+name, err)
+	}
+	for _, c := range credentials {
+		domain.Logins = append(domain.Logins, c.Login)
+	}
+	sort.Strings(domain.Logins)
+
+	domain.Webhooks, err = mg.ListWebhooks(ctx)
+	if err != nil {
+		return domain, newAPIError("reading the webhooks of", "mailgun_domain "+name, err)
+	}
+
+	return domain, nil
+}
+
+// exportNames gives each exported resource a unique Terraform name.
+type exportNames struct {
+	domains map[string]string
+	routes  map[string]string
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+func newExportNames(account exportedAccount) *exportNames {
+	names := &exportNames{domains: make(map[string]string), routes: make(map[string]string)}
+	used := make(map[string]bool)
+	unique := func(name string) string {
+		name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_-")
+		if name == "" || !unicode.IsLetter(rune(name[0])) {
+			name = "r_" + name
+		}
+		candidate := name
+		for i := 2; used[candidate]; i++ {
+			candidate = fmt.Sprintf("%s_%d", name, i)
+		}
+		used[candidate] = true
+		return candidate
+	}
+
+	for _, d := range account.Domains {
+		names.domains[d.Domain.Name] = unique(d.Domain.Name)
+	}
+	used = make(map[string]bool)
+	for _, r := range account.Routes {
+		name := r.Description
+		if name == "" {
+			name = "route_" + r.Id
+		}
+		names.routes[r.Id] = unique(name)
+	}
+	return names
+}
+
+func renderDomains(buf *bytes.Buffer, account exportedAccount, names *exportNames) {
+	defaults := resourceMailgunDomain().Schema
+	htmlFooter := defaults["unsubscribe_tracking_settings_html_footer"].Default.(string)
+	textFooter := defaults["unsubscribe_tracking_settings_text_footer"].Default.(string)
+
+	for i, d := range account.Domains {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "resource \"mailgun_domain\" %s {\n", hclString(names.domains[d.Domain.Name]))
+		hclAttribute(buf, "name", d.Domain.Name)
+		if d.Domain.SpamAction != "" && d.Domain.SpamAction != mailgun.SpamActionDisabled {
+			hclAttribute(buf, "spam_action", string(d.Domain.SpamAction))
+		}
+		if d.Domain.Wildcard {
+			hclAttribute(buf, "wildcard", true)
+		}
+		if d.DKIMKeySize != 1024 {
+			hclAttribute(buf, "dkim_key_size", d.DKIMKeySize)
+		}
+		if d.ForceDKIMAuthority {
+			hclAttribute(buf, "force_dkim_authority", true)
+		}
+		if d.Tracking.Open.Active {
+			hclAttribute(buf, "open_tracking_settings_active", true)
+		}
+		if d.Tracking.Click.Active {
+			hclAttribute(buf, "click_tracking_settings_active", true)
+		}
+		if d.Tracking.Unsubscribe.Active {
+			hclAttribute(buf, "unsubscribe_tracking_settings_active", true)
+		}
+		if normalizeFooter(d.Tracking.Unsubscribe.HTMLFooter) != normalizeFooter(htmlFooter) {
+			hclAttribute(buf, "unsubscribe_tracking_settings_html_footer", d.Tracking.Unsubscribe.HTMLFooter)
+		}
+		if normalizeFooter(d.Tracking.Unsubscribe.TextFooter) != normalizeFooter(textFooter) {
+			hclAttribute(buf, "unsubscribe_tracking_settings_text_footer", d.Tracking.Unsubscribe.TextFooter)
+		}
+		if d.Connection.RequireTLS {
+			hclAttribute(buf, "require_tls", true)
+		}
+		if d.Connection.SkipVerification {
+			hclAttribute(buf, "skip_verification", true)
+		}
+
+		for _, login := range d.Logins {
+			buf.WriteString("\n  credentials {\n  ")
+			hclAttribute(buf, "login", login)
+			buf.WriteString("  }\n")
+		}
+
+		if d.Domain.SMTPPassword != "" {
+			buf.WriteString("\n  # The SMTP password is not exported, set smtp_password to manage it.\n")
+			buf.WriteString("  lifecycle {\n    ignore_changes = [smtp_password]\n  }\n")
+		}
+
+		if len(d.Webhooks) > 0 {
+			buf.WriteString("\n  # Webhooks are not managed by this provider:\n")
+			kinds := make([]string, 0, len(d.Webhooks))
+			for kind := range d.Webhooks {
+				kinds = append(kinds, kind)
+			}
+			sort.Strings(kinds)
+			for _, kind := range kinds {
+				fmt.Fprintf(buf, "  #   %s: %s\n", kind, strings.Join(d.Webhooks[kind], ", "))
+			}
+		}
+		buf.WriteString("}\n")
+	}
+}
+
+func renderRoutes(buf *bytes.Buffer, account exportedAccount, names *exportNames) {
+	for i, r := range account.Routes {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "resource \"mailgun_route\" %s {\n", hclString(names.routes[r.Id]))
+		hclAttribute(buf, "priority", r.Priority)
+		hclAttribute(buf, "description", r.Description)
+		hclAttribute(buf, "expression", r.Expression)
+		hclAttribute(buf, "actions", r.Actions)
+		buf.WriteString("}\n")
+	}
+}
+
+func renderImports(buf *bytes.Buffer, account exportedAccount, names *exportNames, blocks bool) {
+	type resourceImport struct{ address, id string }
+	var imports []resourceImport
+	for _, d := range account.Domains {
+		imports = append(imports, resourceImport{"mailgun_domain." + names.domains[d.Domain.Name], d.Domain.Name})
+	}
+	for _, r := range account.Routes {
+		imports = append(imports, resourceImport{"mailgun_route." + names.routes[r.Id], r.Id})
+	}
+
+	if !blocks {
+		buf.WriteString("#!/bin/sh\nset -e\n\n")
+		for _, i := range imports {
+			fmt.Fprintf(buf, "terraform import %s %s\n", i.address, shellQuote(i.id))
+		}
+		return
+	}
+
+	for n, i := range imports {
+		if n > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "import {\n  to = %s\n  id = %s\n}\n", i.address, hclString(i.id))
+	}
+}
+
+// hclAttribute writes an attribute of a block, indented by two spaces.
+func hclAttribute(buf *bytes.Buffer, name string, value interface{}) {
+	var rendered string
+	switch v := value.(type) {
+	case string:
+		rendered = hclString(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = hclString(s)
+		}
+		rendered = "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		rendered = fmt.Sprint(v)
+	}
+	fmt.Fprintf(buf, "  %s = %s\n", name, rendered)
+}
+
+// hclString quotes s as an HCL string literal, escaping the template sequences.
+func hclString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			b.WriteRune(r)
+			b.WriteRune(r)
+		case !unicode.IsPrint(r) && r > 0xffff:
+			fmt.Fprintf(&b, `\U%08x`, r)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
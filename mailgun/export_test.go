@@ -0,0 +1,105 @@
+package mailgun
+
+import (
+	"bytes"
+	"github.com/mailgun/mailgun-go/v3"
+	"strings"
+	"testing"
+)
+
+func testExportedAccount() exportedAccount {
+	var domain exportedDomain
+	domain.Domain = mailgun.Domain{Name: "mail.example.com", SpamAction: mailgun.SpamActionTag, SMTPPassword: "secret"}
+	domain.DKIMKeySize = 2048
+	domain.Connection.RequireTLS = true
+	domain.Tracking.Click.Active = true
+	domain.Tracking.Unsubscribe.HTMLFooter = "<br>\r\n<p><a href=\"%unsubscribe_url%\">unsubscribe</a></p>"
+	domain.Tracking.Unsubscribe.TextFooter = "Unsubscribe: <%unsubscribe_url%>"
+	domain.Logins = []string{"postmaster@mail.example.com"}
+	domain.Webhooks = map[string][]string{"bounce": {"https://example.com/hooks"}}
+
+	return exportedAccount{
+		Domains: []exportedDomain{domain},
+		Routes: []mailgun.Route{
+			{Id: "r1", Priority: 0, Description: "Support", Expression: `match_recipient("support@example.com")`, Actions: []string{`forward("https://example.com/${id}")`, "stop()"}},
+			{Id: "r2", Priority: 1, Description: "support!", Expression: "catch_all()", Actions: []string{"stop()"}},
+			{Id: "r3", Priority: 2, Expression: "catch_all()", Actions: []string{"stop()"}},
+		},
+	}
+}
+
+func TestRenderDomains(t *testing.T) {
+	account := testExportedAccount()
+	var buf bytes.Buffer
+	renderDomains(&buf, account, newExportNames(account))
+
+	expected := `resource "mailgun_domain" "mail_example_com" {
+  name = "mail.example.com"
+  spam_action = "tag"
+  dkim_key_size = 2048
+  click_tracking_settings_active = true
+  unsubscribe_tracking_settings_text_footer = "Unsubscribe: <%unsubscribe_url%>"
+  require_tls = true
+
+  credentials {
+    login = "postmaster@mail.example.com"
+  }
+
+  # The SMTP password is not exported, set smtp_password to manage it.
+  lifecycle {
+    ignore_changes = [smtp_password]
+  }
+
+  # Webhooks are not managed by this provider:
+  #   bounce: https://example.com/hooks
+}
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected configuration:\n%s", buf.String())
+	}
+}
+
+func TestRenderRoutesAndImports(t *testing.T) {
+	account := testExportedAccount()
+	names := newExportNames(account)
+
+	var routes bytes.Buffer
+	renderRoutes(&routes, account, names)
+	for _, expected := range []string{
+		`resource "mailgun_route" "support" {`,
+		`resource "mailgun_route" "support_2" {`,
+		`resource "mailgun_route" "route_r3" {`,
+		`  actions = ["forward(\"https://example.com/$${id}\")", "stop()"]`,
+	} {
+		if !strings.Contains(routes.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, routes.String())
+		}
+	}
+
+	var script bytes.Buffer
+	renderImports(&script, account, names, false)
+	if !strings.Contains(script.String(), "terraform import mailgun_domain.mail_example_com 'mail.example.com'\n") ||
+		!strings.Contains(script.String(), "terraform import mailgun_route.support_2 'r2'\n") {
+		t.Errorf("unexpected import script:\n%s", script.String())
+	}
+
+	var blocks bytes.Buffer
+	renderImports(&blocks, account, names, true)
+	if !strings.Contains(blocks.String(), "import {\n  to = mailgun_route.route_r3\n  id = \"r3\"\n}\n") {
+		t.Errorf("unexpected import blocks:\n%s", blocks.String())
+	}
+}
+
+func TestHCLString(t *testing.T) {
+	cases := map[string]string{
+		`plain`:           `"plain"`,
+		"a \"b\"\\c\n":    `"a \"b\"\\c\n"`,
+		"${var} %{if} $x": `"$${var} %%{if} $x"`,
+		"bell\a":          `"bell\u0007"`,
+	}
+	for in, expected := range cases {
+		if got := hclString(in); got != expected {
+			t.Errorf("hclString(%q): expected %s, got %s", in, expected, got)
+		}
+	}
+}
@@ -1,37 +1,202 @@
 package mailgun
 
 import (
+	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mailgun/mailgun-go/v3"
+	"time"
 )
 
+// ProviderVersion is the version of the provider, set at build time with
+// -ldflags "-X github.com/fretlink/terraform-provider-mailgun/mailgun.ProviderVersion=...".
+var ProviderVersion = "dev"
+
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"domain": {
 				Type:        schema.TypeString,
-				Required:    true,
-				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_DOMAIN", nil),
-				Description: "domain for mailgun.",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_DOMAIN", ""),
+				Description: "Default domain for the resources scoped to a domain which do not set one.",
 			},
 			"apikey": {
 				Type:        schema.TypeString,
-				Required:    true,
-				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_APIKEY", nil),
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_APIKEY", ""),
 				Description: "API Key for mailgun",
 			},
+			"apikey_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_APIKEY_FILE", ""),
+				Description: "Path of a file holding the API key, used when apikey is not set.",
+			},
+			"apikey_command": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_APIKEY_COMMAND", ""),
+				Description: "Command printing the API key, run with the shell when neither apikey nor apikey_file are set.",
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_PROFILE", ""),
+				Description: "Profile of ~/.mailgun/credentials holding the API key, used when no other source is set.",
+			},
+			"region": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("MAILGUN_REGION", regionUS),
+				ValidateFunc: validation.StringInSlice([]string{regionUS, regionEU}, false),
+				Description:  "Mailgun region of the account, us or eu.",
+			},
+			"subaccount_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_SUBACCOUNT_ID", ""),
+				Description: "Subaccount to act on behalf of.",
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_READ_ONLY", false),
+				Description: "Refuse to create, update or delete anything, e.g. to plan safely against production.",
+			},
+			"rate_limit": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0.0,
+				ValidateFunc: validation.FloatBetween(0, 1000),
+				Description:  "Maximum number of requests per second sent to the Mailgun API by all the resources, unlimited when 0.",
+			},
+			"read_cache_ttl": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  "How long the responses of the list endpoints are cached, e.g. 30s. Writes empty the cache.",
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("MAILGUN_SKIP_CREDENTIALS_VALIDATION", false),
+				Description: "Skip the check of the API key against the Mailgun API when configuring the provider, e.g. to plan offline.",
+			},
+			"max_idle_connections": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     defaultMaxIdleConns,
+				Description: "Maximum number of idle connections kept open to the Mailgun API.",
+			},
+		},
+
+		DataSourcesMap: map[string]*schema.Resource{
+			"mailgun_dmarc_record":     dataSourceMailgunDMARCRecord(),
+			"mailgun_domain_dns":       dataSourceMailgunDomainDNS(),
+			"mailgun_domain_dns_check": dataSourceMailgunDomainDNSCheck(),
+			"mailgun_domain_stats":     dataSourceMailgunDomainStats(),
+			"mailgun_events":           dataSourceMailgunEvents(),
+			"mailgun_spf_record":       dataSourceMailgunSPFRecord(),
+			"mailgun_subaccount":       dataSourceMailgunSubaccount(),
+			"mailgun_tag_stats":        dataSourceMailgunTagStats(),
+			"mailgun_tags":             dataSourceMailgunTags(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"mailgun_domain": resourceMailgunDomain(),
-			"mailgun_route":  resourceMailgunRoute(),
+			"mailgun_api_key":    resourceMailgunAPIKey(),
+			"mailgun_domain":     resourceMailgunDomain(),
+			"mailgun_route":      resourceMailgunRoute(),
+			"mailgun_route_set":  resourceMailgunRouteSet(),
+			"mailgun_subaccount": resourceMailgunSubaccount(),
+			"mailgun_tag":        resourceMailgunTag(),
 		},
+	}
+
+	for name, r := range provider.ResourcesMap {
+		guardReadOnly(name, r)
+	}
 
-		ConfigureFunc: providerConfigure,
+	provider.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		return providerConfigure(d, provider.TerraformVersion)
 	}
+
+	return provider
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	return mailgun.NewMailgun(d.Get("domain").(string), d.Get("apikey").(string)), nil
+// providerConfigure builds the Config of the provider. The API key is taken from the first of
+// apikey, apikey_file, apikey_command and the profile of the shared credentials file which is set.
+func providerConfigure(d *schema.ResourceData, terraformVersion string) (interface{}, error) {
+	apiKey, err := resolveAPIKey(apiKeySources{
+		APIKey:        d.Get("apikey").(string),
+		APIKeyFile:    d.Get("apikey_file").(string),
+		APIKeyCommand: d.Get("apikey_command").(string),
+		Profile:       d.Get("profile").(string),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var readCacheTTL time.Duration
+	if v, ok := d.GetOk("read_cache_ttl"); ok {
+		if readCacheTTL, err = time.ParseDuration(v.(string)); err != nil {
+			return nil, fmt.Errorf("invalid read_cache_ttl: %s", err)
+		}
+	}
+
+	config := Config{
+		APIKey:       apiKey,
+		Domain:       d.Get("domain").(string),
+		SubaccountID: d.Get("subaccount_id").(string),
+		Region:       d.Get("region").(string),
+		MaxIdleConns: d.Get("max_idle_connections").(int),
+		ReadOnly:     d.Get("read_only").(bool),
+		RateLimit:    d.Get("rate_limit").(float64),
+		ReadCacheTTL: readCacheTTL,
+		UserAgent:    userAgent(terraformVersion),
+	}
+
+	if !d.Get("skip_credentials_validation").(bool) {
+		if err := validateCredentials(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	return &config, nil
+}
+
+// guardReadOnly makes the create, update and delete functions of the resource name fail
+// when the provider is read only, before any request is sent.
+func guardReadOnly(name string, r *schema.Resource) {
+	guard := func(action string, f func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+		return func(d *schema.ResourceData, meta interface{}) error {
+			if meta.(*Config).ReadOnly {
+				target := name
+				if d.Id() != "" {
+					target += " " + d.Id()
+				}
+				return fmt.Errorf("cannot %s %s: the provider is read only (read_only or MAILGUN_READ_ONLY)", action, target)
+			}
+			return f(d, meta)
+		}
+	}
+
+	if r.Create != nil {
+		r.Create = guard("create", r.Create)
+	}
+	if r.Update != nil {
+		r.Update = guard("update", r.Update)
+	}
+	if r.Delete != nil {
+		r.Delete = guard("delete", r.Delete)
+	}
+}
+
+func userAgent(terraformVersion string) string {
+	ua := fmt.Sprintf("terraform-provider-mailgun/%s %s", ProviderVersion, mailgun.MailgunGoUserAgent)
+	if terraformVersion != "" {
+		ua = fmt.Sprintf("Terraform/%s %s", terraformVersion, ua)
+	}
+	return ua
 }
@@ -2,8 +2,10 @@ package mailgun
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
@@ -28,6 +30,31 @@ func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
 
+func TestProvider_configureWithoutDomain(t *testing.T) {
+	defer os.Setenv("MAILGUN_DOMAIN", os.Getenv("MAILGUN_DOMAIN"))
+	os.Unsetenv("MAILGUN_DOMAIN")
+
+	provider := Provider().(*schema.Provider)
+	raw := map[string]interface{}{
+		"apikey":                      "key",
+		"skip_credentials_validation": true,
+	}
+
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	err = provider.Configure(terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if domain := provider.Meta().(*Config).Domain; domain != "" {
+		t.Fatalf("expected no domain, got %s", domain)
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	if v := os.Getenv("MAILGUN_DOMAIN"); v == "" {
 		t.Fatal("MAILGUN_DOMAIN must be set for acceptance tests")
@@ -36,3 +63,66 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal("MAILGUN_APIKEY must be set for acceptance tests")
 	}
 }
+
+func TestProvider_readOnly(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	rawConfig, err := config.NewRawConfig(map[string]interface{}{
+		"apikey":                      "key",
+		"read_only":                   true,
+		"skip_credentials_validation": true,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := provider.Configure(terraform.NewResourceConfig(rawConfig)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for name, r := range provider.ResourcesMap {
+		d := r.TestResourceData()
+		if err := r.Create(d, provider.Meta()); err == nil || !strings.Contains(err.Error(), "read only") {
+			t.Errorf("%s: expected create to be refused, got %v", name, err)
+		}
+		d.SetId("id")
+		if r.Update != nil {
+			if err := r.Update(d, provider.Meta()); err == nil || !strings.Contains(err.Error(), "read only") {
+				t.Errorf("%s: expected update to be refused, got %v", name, err)
+			}
+		}
+		if err := r.Delete(d, provider.Meta()); err == nil || !strings.Contains(err.Error(), "read only") {
+			t.Errorf("%s: expected delete to be refused, got %v", name, err)
+		}
+	}
+}
+
+func TestProvider_region(t *testing.T) {
+	defer os.Setenv("MAILGUN_REGION", os.Getenv("MAILGUN_REGION"))
+	os.Unsetenv("MAILGUN_REGION")
+
+	cases := map[string]string{
+		"":   "https://api.mailgun.net/v3",
+		"us": "https://api.mailgun.net/v3",
+		"eu": "https://api.eu.mailgun.net/v3",
+	}
+	for region, expected := range cases {
+		provider := Provider().(*schema.Provider)
+		raw := map[string]interface{}{
+			"apikey":                      "key",
+			"skip_credentials_validation": true,
+		}
+		if region != "" {
+			raw["region"] = region
+		}
+		rawConfig, err := config.NewRawConfig(raw)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := provider.Configure(terraform.NewResourceConfig(rawConfig)); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		if got := provider.Meta().(*Config).PrimaryClient().APIBase(); got != expected {
+			t.Errorf("region %q: expected the API base %s, got %s", region, expected, got)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package mailgun
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// cachedListEndpoints matches the paths of the list endpoints whose responses may be cached.
+var cachedListEndpoints = regexp.MustCompile(`/v[0-9]+/(` +
+	`domains|routes|keys|accounts/subaccounts|` +
+	`domains/[^/]+/(credentials|ips|webhooks)|` +
+	`[^/]+/tags)$`)
+
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// readCacheTransport caches the successful responses of the list endpoints for ttl, so that
+// resources reading the same list in a run share a single request. Any other request than
+// a GET or a HEAD empties the cache, as it may change the lists.
+type readCacheTransport struct {
+	ttl  time.Duration
+	next http.RoundTripper
+
+	mu         sync.Mutex
+	entries    map[string]cachedResponse
+	generation uint64
+}
+
+func newReadCacheTransport(ttl time.Duration, next http.RoundTripper) *readCacheTransport {
+	return &readCacheTransport{ttl: ttl, next: next, entries: make(map[string]cachedResponse)}
+}
+
+func (t *readCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		t.invalidate()
+		defer t.invalidate()
+		return t.next.RoundTrip(req)
+	}
+	if req.Method != http.MethodGet || !cachedListEndpoints.MatchString(req.URL.Path) {
+		return t.next.RoundTrip(req)
+	}
+
+	// Responses depend on the account and subaccount acted on, as well as on the URL.
+	key := req.URL.String() + "\n" + req.Header.Get("Authorization") + "\n" + req.Header.Get(subaccountHeader)
+
+	t.mu.Lock()
+	entry, ok := t.entries[key]
+	generation := t.generation
+	t.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		log.Printf("[DEBUG] using the cached response of %s", req.URL.Path)
+		return entry.response(req), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	entry = cachedResponse{status: resp.StatusCode, header: resp.Header, body: body, expires: time.Now().Add(t.ttl)}
+
+	t.mu.Lock()
+	// A write sent meanwhile may have made the response stale.
+	if generation == t.generation {
+		t.entries[key] = entry
+	}
+	t.mu.Unlock()
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (t *readCacheTransport) invalidate() {
+	t.mu.Lock()
+	t.entries = make(map[string]cachedResponse)
+	t.generation++
+	t.mu.Unlock()
+}
+
+func (c cachedResponse) response(req *http.Request) *http.Response {
+	header := make(http.Header, len(c.header))
+	for k, v := range c.header {
+		header[k] = append([]string(nil), v...)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.status, http.StatusText(c.status)),
+		StatusCode:    c.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,76 @@
+package mailgun
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCacheTransport(t *testing.T) {
+	hits := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.Method+" "+r.URL.Path]++
+		w.Write([]byte(`{"items": []}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newReadCacheTransport(time.Minute, http.DefaultTransport)}
+	send := func(method, path, subaccount string) {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		if subaccount != "" {
+			req.Header.Set(subaccountHeader, subaccount)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "items") {
+			t.Fatalf("unexpected response %d %s", resp.StatusCode, body)
+		}
+	}
+
+	send("GET", "/v3/routes", "")
+	send("GET", "/v3/routes", "")
+	send("GET", "/v3/routes", "subaccount")
+	send("GET", "/v3/domains/example.com", "")
+	send("GET", "/v3/domains/example.com", "")
+	if hits["GET /v3/routes"] != 2 {
+		t.Errorf("expected the routes to be read once per subaccount, got %d", hits["GET /v3/routes"])
+	}
+	if hits["GET /v3/domains/example.com"] != 2 {
+		t.Errorf("expected the domain not to be cached, got %d", hits["GET /v3/domains/example.com"])
+	}
+
+	send("POST", "/v3/routes", "")
+	send("GET", "/v3/routes", "")
+	if hits["GET /v3/routes"] != 3 {
+		t.Errorf("expected the write to empty the cache, got %d", hits["GET /v3/routes"])
+	}
+}
+
+func TestReadCacheTransport_expires(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{"items": []}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newReadCacheTransport(10*time.Millisecond, http.DefaultTransport)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/v3/domains/example.com/credentials")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		resp.Body.Close()
+		time.Sleep(20 * time.Millisecond)
+	}
+	if hits != 2 {
+		t.Errorf("expected the cached response to expire, got %d hits", hits)
+	}
+}
@@ -0,0 +1,265 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/mailgun/mailgun-go/v3"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const keysEndpoint = "/v1/keys"
+
+// apiKey is a key as returned by the Mailgun keys API.
+type apiKey struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"`
+	Role        string `json:"role"`
+	DomainName  string `json:"domain_name"`
+	Requestor   string `json:"requestor"`
+	UserName    string `json:"user_name"`
+	CreatedAt   string `json:"created_at"`
+	ExpiresAt   string `json:"expires_at"`
+	IsDisabled  bool   `json:"is_disabled"`
+	Secret      string `json:"secret"`
+}
+
+type apiKeyResponse struct {
+	Key     apiKey `json:"key"`
+	Message string `json:"message"`
+}
+
+type apiKeysListResponse struct {
+	TotalCount int      `json:"total_count"`
+	Items      []apiKey `json:"items"`
+}
+
+func resourceMailgunAPIKey() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateAPIKey,
+		Delete: DeleteAPIKey,
+		Read:   ReadAPIKey,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"role": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"admin", "basic", "sending", "developer"}, false),
+			},
+
+			"kind": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "user",
+				ValidateFunc: validation.StringInSlice([]string{"domain", "user", "web"}, false),
+			},
+
+			"domain": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"expiration": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"secret": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"requestor": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"created_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"expires_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"disabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"subaccount_id": subaccountIDSchema(),
+		},
+	}
+}
+
+func CreateAPIKey(d *schema.ResourceData, meta interface{}) error {
+	mg := resourceClient(d, meta, "")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	kind := d.Get("kind").(string)
+	domainName := d.Get("domain").(string)
+	if kind == "domain" {
+		var err error
+		domainName, err = meta.(*Config).ResolveDomain(domainName)
+		if err != nil {
+			return fmt.Errorf("Error creating mailgun api key of kind domain: %s", err)
+		}
+	}
+
+	log.Printf("[DEBUG] creating mailgun %s api key for %s", d.Get("role").(string), domainName)
+
+	params := url.Values{}
+	params.Set("role", d.Get("role").(string))
+	params.Set("kind", kind)
+	if domainName != "" {
+		params.Set("domain_name", domainName)
+	}
+	if v, ok := d.GetOk("description"); ok {
+		params.Set("description", v.(string))
+	}
+	if v, ok := d.GetOk("expiration"); ok {
+		params.Set("expiration", strconv.Itoa(v.(int)))
+	}
+
+	var creationResponse apiKeyResponse
+	err := apiRequest(ctx, mg, http.MethodPost, keysEndpoint, params, &creationResponse)
+	if err != nil {
+		return newAPIError("creating", "mailgun_api_key", err)
+	}
+
+	d.SetId(creationResponse.Key.ID)
+	d.Set("secret", creationResponse.Key.Secret)
+
+	return ReadAPIKey(d, meta)
+}
+
+func DeleteAPIKey(d *schema.ResourceData, meta interface{}) error {
+	mg := resourceClient(d, meta, "")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	log.Printf("[DEBUG] Deleting mailgun api key: %s", d.Id())
+
+	err := apiRequest(ctx, mg, http.MethodDelete, keysEndpoint+"/"+url.PathEscape(d.Id()), nil, nil)
+	if err != nil && mailgun.GetStatusFromErr(err) != http.StatusNotFound {
+		return newAPIError("deleting", "mailgun_api_key "+d.Id(), err)
+	}
+
+	return nil
+}
+
+func ReadAPIKey(d *schema.ResourceData, meta interface{}) error {
+	mg := resourceClient(d, meta, "")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	key, err := getAPIKey(ctx, mg, d.Id(), d.Get("domain").(string))
+	if err != nil {
+		return newAPIError("reading", "mailgun_api_key "+d.Id(), err)
+	}
+
+	if key == nil {
+		log.Printf("[WARN] mailgun api key %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("role", key.Role)
+	d.Set("kind", key.Kind)
+	d.Set("domain", key.DomainName)
+	d.Set("description", key.Description)
+	d.Set("requestor", key.Requestor)
+	d.Set("created_at", key.CreatedAt)
+	d.Set("expires_at", key.ExpiresAt)
+	d.Set("disabled", key.IsDisabled)
+
+	// Mailgun does not return the expiration the key was created with, only its dates.
+	if key.ExpiresAt == "" {
+		d.Set("expiration", 0)
+	} else if expiration, err := apiKeyExpiration(key); err != nil {
+		log.Printf("[WARN] unable to read the expiration of mailgun api key %s: %s", d.Id(), err)
+	} else {
+		d.Set("expiration", expiration)
+	}
+
+	return nil
+}
+
+// apiKeyTimeLayouts lists the layouts of the dates returned by the keys API.
+var apiKeyTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", time.RFC1123, time.RFC1123Z}
+
+func parseAPIKeyTime(value string) (time.Time, error) {
+	for _, layout := range apiKeyTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format: %q", value)
+}
+
+// apiKeyExpiration returns the lifetime in seconds of a key which expires, from its dates of creation and expiration.
+func apiKeyExpiration(key *apiKey) (int, error) {
+	createdAt, err := parseAPIKeyTime(key.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	expiresAt, err := parseAPIKeyTime(key.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return int(expiresAt.Sub(createdAt).Round(time.Second) / time.Second), nil
+}
+
+// getAPIKey looks an api key up by id, as the keys API can only list them, going through
+// every page of the list. It returns nil when no such key exists.
+func getAPIKey(ctx context.Context, mg *mailgun.MailgunImpl, id, domainName string) (*apiKey, error) {
+	const limit = 100
+	for skip := 0; ; skip += limit {
+		params := url.Values{}
+		if domainName != "" {
+			params.Set("domain_name", domainName)
+		}
+		params.Set("limit", strconv.Itoa(limit))
+		params.Set("skip", strconv.Itoa(skip))
+
+		var keys apiKeysListResponse
+		err := apiRequest(ctx, mg, http.MethodGet, keysEndpoint, params, &keys)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, k := range keys.Items {
+			if k.ID == id {
+				return &keys.Items[i], nil
+			}
+		}
+		if len(keys.Items) < limit || skip+len(keys.Items) >= keys.TotalCount {
+			return nil, nil
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package mailgun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAccMailgunAPIKey_basic(t *testing.T) {
+	var key apiKey
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccAPIKeyCheckDestroy(&key),
+		Steps: []resource.TestStep{
+			{
+				Config: interpolateTerraformTemplateDomain(testAccAPIKeyConfig_basic),
+				Check: resource.ComposeTestCheckFunc(
+					testAccAPIKeyCheckExists("mailgun_api_key.exemple", &key),
+					resource.TestCheckResourceAttr("mailgun_api_key.exemple", "role", "sending"),
+					resource.TestCheckResourceAttr("mailgun_api_key.exemple", "kind", "domain"),
+					resource.TestCheckResourceAttrSet("mailgun_api_key.exemple", "secret"),
+				),
+			},
+		},
+	})
+}
+
+func TestAPIKey_importBasic(t *testing.T) {
+	var key apiKey
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccAPIKeyCheckDestroy(&key),
+		Steps: []resource.TestStep{
+			{
+				Config: interpolateTerraformTemplateDomain(testAccAPIKeyConfig_basic),
+				Check: resource.ComposeTestCheckFunc(
+					testAccAPIKeyCheckExists("mailgun_api_key.exemple", &key),
+				),
+			},
+			{
+				ResourceName:            "mailgun_api_key.exemple",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"secret"},
+			},
+		},
+	})
+}
+
+// newFakeAPIKeysServer lists count keys, key-0 to key-<count-1>, by pages of at most limit keys.
+// Each key expires an hour after its creation.
+func newFakeAPIKeysServer(count int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != keysEndpoint {
+			http.NotFound(w, r)
+			return
+		}
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit == 0 {
+			limit = 10
+		}
+		var items []apiKey
+		for i := skip; i < count && i < skip+limit; i++ {
+			items = append(items, apiKey{
+				ID:        fmt.Sprintf("key-%d", i),
+				Role:      "sending",
+				Kind:      "domain",
+				CreatedAt: "2019-10-19T16:00:00",
+				ExpiresAt: "2019-10-19T17:00:00",
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(apiKeysListResponse{TotalCount: count, Items: items})
+	}))
+}
+
+func TestReadAPIKey_fakeServer(t *testing.T) {
+	server := newFakeAPIKeysServer(250)
+	defer server.Close()
+	config := &Config{APIKey: "key", apiBase: server.URL + "/v3"}
+
+	d := resourceMailgunAPIKey().TestResourceData()
+	d.SetId("key-242")
+	if err := ReadAPIKey(d, config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Id() != "key-242" {
+		t.Fatal("expected the key of the last page to be found")
+	}
+	if got := d.Get("expiration"); got != 3600 {
+		t.Errorf("expected the expiration to be read back as 3600, got %v", got)
+	}
+
+	d = resourceMailgunAPIKey().TestResourceData()
+	d.SetId("key-250")
+	if err := ReadAPIKey(d, config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Id() != "" {
+		t.Error("expected a missing key to be removed from the state")
+	}
+}
+
+func testAccAPIKeyCheckExists(rn string, key *apiKey) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[rn]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", rn)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("api key ID not set")
+		}
+
+		mg := testAccProvider.Meta().(*Config).Client("", "")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+
+		gotKey, err := getAPIKey(ctx, mg, rs.Primary.ID, rs.Primary.Attributes["domain"])
+		if err != nil {
+			return fmt.Errorf("error getting api key: %s", err)
+		}
+		if gotKey == nil {
+			return fmt.Errorf("api key %s not found", rs.Primary.ID)
+		}
+
+		*key = *gotKey
+
+		return nil
+	}
+}
+
+func testAccAPIKeyCheckDestroy(key *apiKey) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		mg := testAccProvider.Meta().(*Config).Client("", "")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+
+		gotKey, err := getAPIKey(ctx, mg, key.ID, key.DomainName)
+		if err != nil {
+			return err
+		}
+		if gotKey != nil {
+			return fmt.Errorf("api key still exists")
+		}
+
+		return nil
+	}
+}
+
+const testAccAPIKeyConfig_basic = `
+resource "mailgun_api_key" "exemple" {
+	role        = "sending"
+	kind        = "domain"
+	domain      = "%s"
+	description = "terraform acceptance test"
+	expiration  = 3600
+}
+`
@@ -2,12 +2,20 @@ package mailgun
 
 import (
 	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/customdiff"
+	"github.com/hashicorp/terraform/helper/hashcode"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/mailgun/mailgun-go/v3"
 	"log"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
 )
 
 func resourceMailgunDomain() *schema.Resource {
@@ -19,6 +27,19 @@ func resourceMailgunDomain() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: ImportStatePassthroughDomain,
 		},
+		CustomizeDiff: customdiff.All(
+			customizeDomainDNSRecordsDiff,
+			customizeDomainDeletionProtectionDiff,
+		),
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceMailgunDomainV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceMailgunDomainStateUpgradeV0,
+			},
+		},
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -77,16 +98,18 @@ func resourceMailgunDomain() *schema.Resource {
 			},
 
 			"ips": &schema.Schema{
-				Type:     schema.TypeList,
-				Computed: true,
-				ForceNew: true,
-				Optional: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:             schema.TypeList,
+				Computed:         true,
+				ForceNew:         true,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				DiffSuppressFunc: suppressIpsOrderDiff,
 			},
 
 			"credentials": &schema.Schema{
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
+				Set:      credentialHash,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"created_at": &schema.Schema{
@@ -94,12 +117,14 @@ func resourceMailgunDomain() *schema.Resource {
 							Computed: true,
 						},
 						"login": &schema.Schema{
-							Type:     schema.TypeString,
-							Required: true,
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressLoginDomainDiff,
 						},
 						"password": &schema.Schema{
-							Type:     schema.TypeString,
-							Optional: true,
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressUnknownCredentialPassword,
 						},
 					},
 				},
@@ -123,14 +148,16 @@ func resourceMailgunDomain() *schema.Resource {
 				Default:  false,
 			},
 			"unsubscribe_tracking_settings_html_footer": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "\n<br>\n<p><a href=\"%unsubscribe_url%\">unsubscribe</a></p>\n",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "\n<br>\n<p><a href=\"%unsubscribe_url%\">unsubscribe</a></p>\n",
+				DiffSuppressFunc: suppressFooterWhitespaceDiff,
 			},
 			"unsubscribe_tracking_settings_text_footer": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "\n\nTo unsubscribe click: <%unsubscribe_url%>\n\n",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "\n\nTo unsubscribe click: <%unsubscribe_url%>\n\n",
+				DiffSuppressFunc: suppressFooterWhitespaceDiff,
 			},
 
 			"require_tls": &schema.Schema{
@@ -174,6 +201,22 @@ func resourceMailgunDomain() *schema.Resource {
 				},
 			},
 
+			"subaccount_id": subaccountIDSchema(),
+
+			"deletion_protection": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"dns_records":      dnsRecordMapSchema("Data of the DNS records of the domain, keyed by purpose."),
+			"dns_record_names": dnsRecordMapSchema("Names of the DNS records of the domain, keyed by purpose."),
+			"dns_record_types": dnsRecordMapSchema("Types of the DNS records of the domain, keyed by purpose."),
+
+			"predictable_dns_records":      dnsRecordMapSchema("Data of the DNS records of the domain known before it is created, keyed by purpose."),
+			"predictable_dns_record_names": dnsRecordMapSchema("Names of the DNS records of the domain known before it is created, keyed by purpose."),
+			"predictable_dns_record_types": dnsRecordMapSchema("Types of the DNS records of the domain known before it is created, keyed by purpose."),
+
 			"sending_records": &schema.Schema{
 				Type:     schema.TypeList,
 				Computed: true,
@@ -215,11 +258,11 @@ func interfaceToStringTab(i interface{}) []string {
 	return aString
 }
 func CreateDomain(d *schema.ResourceData, meta interface{}) error {
-	mg := meta.(*mailgun.MailgunImpl)
+	mg := resourceClient(d, meta, d.Get("name").(string))
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
-	log.Printf("[DEBUG] creating  mailgun domain: %s", d.Id())
+	log.Printf("[DEBUG] creating  mailgun domain: %s", d.Get("name").(string))
 
 	creationResponse, err := mg.CreateDomain(ctx, d.Get("name").(string), &mailgun.CreateDomainOptions{
 		Password:           d.Get("smtp_password").(string),
@@ -231,37 +274,35 @@ func CreateDomain(d *schema.ResourceData, meta interface{}) error {
 	})
 
 	if err != nil {
-		return fmt.Errorf("Error creating mailgun domain: %s", err.Error())
+		return newAPIError("creating", "mailgun_domain "+d.Get("name").(string), err)
 	}
 
-	mg = mailgun.NewMailgun(creationResponse.Domain.Name, mg.APIKey())
-
-	for _, i := range d.Get("credentials").([]interface{}) {
+	for _, i := range d.Get("credentials").(*schema.Set).List() {
 		credential := i.(map[string]interface{})
 		err = mg.CreateCredential(ctx, credential["login"].(string), credential["password"].(string))
 		if err != nil {
-			return fmt.Errorf("Error creating mailgun credential: %s", err.Error())
+			return newAPIError("creating the credential "+credential["login"].(string)+" of", "mailgun_domain "+d.Get("name").(string), err)
 		}
 	}
 
 	err = mg.UpdateUnsubscribeTracking(ctx, creationResponse.Domain.Name, boolToString(d.Get("unsubscribe_tracking_settings_active").(bool)), d.Get("unsubscribe_tracking_settings_html_footer").(string), d.Get("unsubscribe_tracking_settings_text_footer").(string))
 	if err != nil {
-		return fmt.Errorf("Error updating mailgun unsubscribe tracking settings: %s", err.Error())
+		return newAPIError("updating the unsubscribe tracking settings of", "mailgun_domain "+creationResponse.Domain.Name, err)
 	}
 
 	err = mg.UpdateOpenTracking(ctx, creationResponse.Domain.Name, boolToString(d.Get("open_tracking_settings_active").(bool)))
 	if err != nil {
-		return fmt.Errorf("Error updating mailgun open tracking settings: %s", err.Error())
+		return newAPIError("updating the open tracking settings of", "mailgun_domain "+creationResponse.Domain.Name, err)
 	}
 
 	err = mg.UpdateClickTracking(ctx, creationResponse.Domain.Name, boolToString(d.Get("click_tracking_settings_active").(bool)))
 	if err != nil {
-		return fmt.Errorf("Error updating mailgun click tracking settings: %s", err.Error())
+		return newAPIError("updating the click tracking settings of", "mailgun_domain "+creationResponse.Domain.Name, err)
 	}
 
 	err = mg.UpdateDomainConnection(ctx, creationResponse.Domain.Name, mailgun.DomainConnection{RequireTLS: d.Get("require_tls").(bool), SkipVerification: d.Get("skip_verification").(bool)})
 	if err != nil {
-		return fmt.Errorf("Error updating mailgun connexion settings: %s", err.Error())
+		return newAPIError("updating the connection settings of", "mailgun_domain "+creationResponse.Domain.Name, err)
 	}
 
 	d.SetId(creationResponse.Domain.Name)
@@ -270,81 +311,65 @@ func CreateDomain(d *schema.ResourceData, meta interface{}) error {
 }
 
 func UpdateDomain(d *schema.ResourceData, meta interface{}) error {
-	mg := meta.(*mailgun.MailgunImpl)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 	domainName := d.Get("name").(string)
-	mg = mailgun.NewMailgun(domainName, mg.APIKey())
+	mg := resourceClient(d, meta, domainName)
 
 	log.Printf("[DEBUG] updating  mailgun domain: %s", d.Id())
 
 	if d.HasChange("unsubscribe_tracking_settings_active") || d.HasChange("unsubscribe_tracking_settings_html_footer") || d.HasChange("unsubscribe_tracking_settings_text_footer") {
 		err := mg.UpdateUnsubscribeTracking(ctx, domainName, boolToString(d.Get("unsubscribe_tracking_settings_active").(bool)), d.Get("unsubscribe_tracking_settings_html_footer").(string), d.Get("unsubscribe_tracking_settings_text_footer").(string))
 		if err != nil {
-			return fmt.Errorf("Error updating mailgun unsubscribe tracking settings: %s", err.Error())
+			return newAPIError("updating the unsubscribe tracking settings of", "mailgun_domain "+domainName, err)
 		}
 	}
 	if d.HasChange("open_tracking_settings_active") {
 		err := mg.UpdateOpenTracking(ctx, domainName, boolToString(d.Get("open_tracking_settings_active").(bool)))
 		if err != nil {
-			return fmt.Errorf("Error updating mailgun open tracking settings: %s", err.Error())
+			return newAPIError("updating the open tracking settings of", "mailgun_domain "+domainName, err)
 		}
 	}
 
 	if d.HasChange("click_tracking_settings_active") {
 		err := mg.UpdateClickTracking(ctx, domainName, boolToString(d.Get("click_tracking_settings_active").(bool)))
 		if err != nil {
-			return fmt.Errorf("Error updating mailgun click tracking settings: %s", err.Error())
+			return newAPIError("updating the click tracking settings of", "mailgun_domain "+domainName, err)
 		}
 	}
 
 	if d.HasChange("require_tls") || d.HasChange("skip_verification") {
 		err := mg.UpdateDomainConnection(ctx, domainName, mailgun.DomainConnection{RequireTLS: d.Get("require_tls").(bool), SkipVerification: d.Get("skip_verification").(bool)})
 		if err != nil {
-			return fmt.Errorf("Error updating mailgun connexion settings: %s", err.Error())
+			return newAPIError("updating the connection settings of", "mailgun_domain "+domainName, err)
 		}
 	}
 
 	if d.HasChange("credentials") {
 		old, new := d.GetChange("credentials")
-		for _, i := range old.([]interface{}) {
-			oldCredential := i.(map[string]interface{})
-			found := false
-			for _, j := range new.([]interface{}) {
-				newCredential := j.(map[string]interface{})
-				if oldCredential["login"] == newCredential["login"] {
-					found = true
-					if oldCredential["password"] != newCredential["password"] && newCredential["password"] != "" {
-						err := mg.ChangeCredentialPassword(ctx, oldCredential["login"].(string), newCredential["password"].(string))
-						if err != nil {
-							return fmt.Errorf("Error updating mailgun credential password: %s", err.Error())
-						}
-					}
-					break
-				}
-			}
-			if !found {
-				err := mg.DeleteCredential(ctx, oldCredential["login"].(string))
+		oldCredentials := credentialsByLogin(old.(*schema.Set))
+		newCredentials := credentialsByLogin(new.(*schema.Set))
+
+		for login := range oldCredentials {
+			if _, ok := newCredentials[login]; !ok {
+				err := mg.DeleteCredential(ctx, login)
 				if err != nil {
-					return fmt.Errorf("Error deleting mailgun credential : %s", err.Error())
+					return newAPIError("deleting the credential "+login+" of", "mailgun_domain "+domainName, err)
 				}
 			}
 		}
 
-		for _, i := range new.([]interface{}) {
-			newCredential := i.(map[string]interface{})
-			found := false
-			for _, j := range old.([]interface{}) {
-				oldCredential := j.(map[string]interface{})
-				if oldCredential["login"] == newCredential["login"] {
-					found = true
-					break
+		for login, newCredential := range newCredentials {
+			oldCredential, ok := oldCredentials[login]
+			if !ok {
+				err := mg.CreateCredential(ctx, login, newCredential["password"].(string))
+				if err != nil {
+					return newAPIError("creating the credential "+login+" of", "mailgun_domain "+domainName, err)
 				}
-			}
-			if !found {
-				err := mg.CreateCredential(ctx, newCredential["login"].(string), newCredential["password"].(string))
+			} else if oldCredential["password"] != newCredential["password"] && newCredential["password"] != "" {
+				err := mg.ChangeCredentialPassword(ctx, login, newCredential["password"].(string))
 				if err != nil {
-					return fmt.Errorf("Error creating  mailgun credential : %s", err.Error())
+					return newAPIError("updating the password of the credential "+login+" of", "mailgun_domain "+domainName, err)
 				}
 			}
 		}
@@ -354,7 +379,11 @@ func UpdateDomain(d *schema.ResourceData, meta interface{}) error {
 }
 
 func DeleteDomain(d *schema.ResourceData, meta interface{}) error {
-	mg := meta.(*mailgun.MailgunImpl)
+	if d.Get("deletion_protection").(bool) {
+		return fmt.Errorf("Error deleting mailgun domain %s: deletion_protection is enabled, disable it and apply before destroying the domain", d.Id())
+	}
+
+	mg := resourceClient(d, meta, d.Get("name").(string))
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
@@ -362,20 +391,64 @@ func DeleteDomain(d *schema.ResourceData, meta interface{}) error {
 
 	err := mg.DeleteDomain(ctx, d.Get("name").(string))
 
-	return err
+	return newAPIError("deleting", "mailgun_domain "+d.Id(), err)
 }
 
 func ReadDomain(d *schema.ResourceData, meta interface{}) error {
-	mg := meta.(*mailgun.MailgunImpl)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*120)
 	defer cancel()
 	domainName := d.Id()
-	mg = mailgun.NewMailgun(domainName, mg.APIKey())
-
-	domainResponse, err := mg.GetDomain(ctx, domainName)
+	mg := resourceClient(d, meta, domainName)
+
+	var (
+		domainResponse      mailgun.DomainResponse
+		domainConnection    mailgun.DomainConnection
+		domainTracking      mailgun.DomainTracking
+		ipAddress           []mailgun.IPAddress
+		credentialsResponse []mailgun.Credential
+	)
+	// The details of the domain are read with independent requests, sent concurrently.
+	err := runConcurrently(ctx, domainReadConcurrency,
+		func(ctx context.Context) (err error) {
+			domainResponse, err = mg.GetDomain(ctx, domainName)
+			if err != nil {
+				return newAPIError("reading", "mailgun_domain "+domainName, err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			domainConnection, err = mg.GetDomainConnection(ctx, domainName)
+			if err != nil {
+				return newAPIError("reading the connection settings of", "mailgun_domain "+domainName, err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			domainTracking, err = mg.GetDomainTracking(ctx, domainName)
+			if err != nil {
+				return newAPIError("reading the tracking settings of", "mailgun_domain "+domainName, err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			ipAddress, err = getIps(ctx, mg)
+			if err != nil {
+				return newAPIError("reading the ips of", "mailgun_domain "+domainName, err)
+			}
+			return nil
+		},
+		func(ctx context.Context) (err error) {
+			credentialsResponse, err = listCredentials(ctx, mg)
+			if err != nil {
+				return newAPIError("reading the credentials of", "mailgun_domain "+domainName, err)
+			}
+			return nil
+		},
+	)
 	if err != nil {
-		return fmt.Errorf("Error Getting mailgun domain Details for %s: Error: %s", d.Id(), err)
+		return err
 	}
+
 	d.Set("created_at", domainResponse.Domain.CreatedAt.String())
 	d.Set("smtp_login", domainResponse.Domain.SMTPLogin)
 	d.Set("name", domainResponse.Domain.Name)
@@ -406,18 +479,19 @@ func ReadDomain(d *schema.ResourceData, meta interface{}) error {
 	}
 	d.Set("sending_records", simpleSendingRecords)
 
-	domainConnection, err := mg.GetDomainConnection(ctx, domainName)
-	if err != nil {
-		return fmt.Errorf("Error Getting mailgun domain connection  Details for %s: Error: %s", d.Id(), err)
-	}
+	records := dnsRecordsByPurpose(domainName, domainResponse.SendingDNSRecords, domainResponse.ReceivingDNSRecords)
+	values, names, types := dnsRecordMaps(records)
+	d.Set("dns_records", values)
+	d.Set("dns_record_names", names)
+	d.Set("dns_record_types", types)
+	values, names, types = dnsRecordMaps(predictableDNSRecords(records))
+	d.Set("predictable_dns_records", values)
+	d.Set("predictable_dns_record_names", names)
+	d.Set("predictable_dns_record_types", types)
+
 	d.Set("require_tls", domainConnection.RequireTLS)
 	d.Set("skip_verification", domainConnection.SkipVerification)
 
-	domainTracking, err := mg.GetDomainTracking(ctx, domainName)
-	if err != nil {
-		return fmt.Errorf("Error Getting mailgun domain tracking Details for %s: Error: %s", d.Id(), err)
-	}
-
 	d.Set("open_tracking_settings_active", domainTracking.Open.Active)
 
 	d.Set("click_tracking_settings_active", domainTracking.Click.Active)
@@ -425,11 +499,6 @@ func ReadDomain(d *schema.ResourceData, meta interface{}) error {
 	d.Set("unsubscribe_tracking_settings_html_footer", domainTracking.Unsubscribe.HTMLFooter)
 	d.Set("unsubscribe_tracking_settings_text_footer", domainTracking.Unsubscribe.TextFooter)
 
-	ipAddress, err := getIps(ctx, mg)
-
-	if err != nil {
-		return fmt.Errorf("Error Getting mailgun domain ips1 for %s: Error: %s", d.Id(), err)
-	}
 	ips := make([]string, len(ipAddress))
 	for i, r := range ipAddress {
 		ips[i] = r.IP
@@ -437,20 +506,17 @@ func ReadDomain(d *schema.ResourceData, meta interface{}) error {
 	}
 	d.Set("ips", ips)
 
-	credentialsResponse, err := ListCredentials(domainName, mg.APIKey())
-	if err != nil {
-		return fmt.Errorf("Error Getting mailgun credentials for %s: Error: %s", d.Id(), err)
-	}
-
 	credentials := make([]map[string]interface{}, len(credentialsResponse))
-	credentialsConf := d.Get("credentials").([]interface{})
+	credentialsConf := d.Get("credentials").(*schema.Set).List()
 	for i, r := range credentialsResponse {
 		credentials[i] = make(map[string]interface{})
 		credentials[i]["created_at"] = r.CreatedAt.String()
-		credentials[i]["login"] = r.Login
-		for _, c:= range credentialsConf {
-			conf:=c.(map[string]interface{})
-			if conf["login"] == credentials[i]["login"] {
+		// Mailgun returns full logins, while they may be configured without the domain:
+		// the short form is stored, with or without a configuration, e.g. after an import.
+		credentials[i]["login"] = shortLogin(r.Login)
+		for _, c := range credentialsConf {
+			conf := c.(map[string]interface{})
+			if shortLogin(conf["login"].(string)) == shortLogin(r.Login) {
 				credentials[i]["password"] = conf["password"]
 			}
 		}
@@ -463,6 +529,60 @@ func ReadDomain(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
+// customizeDomainDeletionProtectionDiff fails the plans replacing a domain protected against deletion.
+// The protection of the state is checked, so that it has to be disabled in a previous apply.
+func customizeDomainDeletionProtectionDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	if protected, _ := d.GetChange("deletion_protection"); !protected.(bool) {
+		return nil
+	}
+
+	schemas := resourceMailgunDomain().Schema
+	forceNew := make(map[string]bool)
+	for _, k := range d.GetChangedKeysPrefix("") {
+		name := strings.SplitN(k, ".", 2)[0]
+		if s, ok := schemas[name]; ok && s.ForceNew {
+			forceNew[name] = true
+		}
+	}
+	if len(forceNew) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(forceNew))
+	for k := range forceNew {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("mailgun domain %s has deletion_protection enabled and cannot be replaced, as required by the changes to %s: "+
+		"disable deletion_protection and apply first", d.Id(), strings.Join(keys, ", "))
+}
+
+// credentialHash identifies credentials by their login only, so that changing a password
+// updates the credential in place. Logins with and without the domain are the same credential.
+func credentialHash(v interface{}) int {
+	return hashcode.String(shortLogin(v.(map[string]interface{})["login"].(string)))
+}
+
+// shortLogin returns login without its trailing @<domain>, if any.
+func shortLogin(login string) string {
+	if i := strings.LastIndex(login, "@"); i >= 0 {
+		return login[:i]
+	}
+	return login
+}
+
+func credentialsByLogin(credentials *schema.Set) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{}, credentials.Len())
+	for _, i := range credentials.List() {
+		credential := i.(map[string]interface{})
+		result[shortLogin(credential["login"].(string))] = credential
+	}
+	return result
+}
+
 func boolToString(b bool) string {
 	if b {
 		return "true"
@@ -470,13 +590,16 @@ func boolToString(b bool) string {
 	return "false"
 }
 
-func ListCredentials(domain, apiKey string) ([]mailgun.Credential, error) {
-	mg := mailgun.NewMailgun(domain, apiKey)
-	it := mg.ListCredentials(nil)
-
+func ListCredentials(mg *mailgun.MailgunImpl) ([]mailgun.Credential, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
+	return listCredentials(ctx, mg)
+}
+
+func listCredentials(ctx context.Context, mg *mailgun.MailgunImpl) ([]mailgun.Credential, error) {
+	it := mg.ListCredentials(nil)
+
 	var page, result []mailgun.Credential
 	for it.Next(ctx, &page) {
 		result = append(result, page...)
@@ -489,27 +612,172 @@ func ListCredentials(domain, apiKey string) ([]mailgun.Credential, error) {
 }
 
 func ImportStatePassthroughDomain(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	if _, ok := d.GetOk("dkim_key_size"); !ok {
-		d.Set("dkim_key_size", 1024)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	domainName := d.Id()
+	mg := resourceClient(d, meta, domainName)
+
+	log.Printf("[DEBUG] importing mailgun domain: %s", domainName)
+
+	domainResponse, err := mg.GetDomain(ctx, domainName)
+	if err != nil {
+		return nil, newAPIError("importing", "mailgun_domain "+domainName, err)
 	}
 
-	if _, ok := d.GetOk("force_dkim_authority"); !ok {
-		d.Set("force_dkim_authority", false)
+	dkimKeySize := 1024
+	if record := findDkimRecord(domainResponse.SendingDNSRecords); record != nil {
+		size, err := dkimKeySizeFromRecord(record.Value)
+		if err != nil {
+			log.Printf("[WARN] unable to read DKIM key size for %s, assuming %d: %s", domainName, dkimKeySize, err)
+		} else {
+			dkimKeySize = size
+		}
 	}
+	d.Set("dkim_key_size", dkimKeySize)
+
+	forceDkimAuthority, err := isDkimAuthorityForced(ctx, mg, domainName, domainResponse.SendingDNSRecords)
+	if err != nil {
+		return nil, newAPIError("reading the DKIM authority of", "mailgun_domain "+domainName, err)
+	}
+	d.Set("force_dkim_authority", forceDkimAuthority)
+	d.Set("deletion_protection", false)
+
 	return []*schema.ResourceData{d}, nil
 }
 
-func getIps(ctx context.Context,mg *mailgun.MailgunImpl) ([]mailgun.IPAddress, error){
+// findDkimRecord returns the DKIM TXT record among the sending records of a domain, if any.
+func findDkimRecord(records []mailgun.DNSRecord) *mailgun.DNSRecord {
+	for i, r := range records {
+		if strings.EqualFold(r.RecordType, "TXT") && strings.Contains(r.Name, "._domainkey.") {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+// dkimKeySizeFromRecord reads the size in bits of the public key published in a DKIM TXT record
+// such as "k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQ...".
+func dkimKeySizeFromRecord(value string) (int, error) {
+	var encodedKey string
+	for _, tag := range strings.Split(value, ";") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "p=") {
+			encodedKey = strings.Join(strings.Fields(strings.TrimPrefix(tag, "p=")), "")
+		}
+	}
+	if encodedKey == "" {
+		return 0, fmt.Errorf("no public key found in DKIM record")
+	}
+
+	der, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return 0, fmt.Errorf("invalid DKIM public key encoding: %s", err)
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return 0, fmt.Errorf("DKIM public key is not an RSA key")
+		}
+		return rsaKey.N.BitLen(), nil
+	}
+
+	rsaKey, err := x509.ParsePKCS1PublicKey(der)
+	if err != nil {
+		return 0, fmt.Errorf("invalid DKIM public key: %s", err)
+	}
+	return rsaKey.N.BitLen(), nil
+}
+
+// isDkimAuthorityForced tells whether a domain signs with its own DKIM key although one of its
+// parent domains is registered on the same account, which only happens when the domain was
+// created with force_dkim_authority. A domain created with force_dkim_authority while none of its
+// parent domains is registered cannot be told apart, and is reported as not forced.
+func isDkimAuthorityForced(ctx context.Context, mg *mailgun.MailgunImpl, domainName string, records []mailgun.DNSRecord) (bool, error) {
+	record := findDkimRecord(records)
+	if record == nil || !strings.HasSuffix(strings.TrimSuffix(record.Name, "."), "._domainkey."+domainName) {
+		return false, nil
+	}
+
+	labels := strings.Split(domainName, ".")
+	for i := 1; i < len(labels)-1; i++ {
+		parent := strings.Join(labels[i:], ".")
+		_, err := mg.GetDomain(ctx, parent)
+		if err == nil {
+			return true, nil
+		}
+		if mailgun.GetStatusFromErr(err) != 404 {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// suppressIpsOrderDiff ignores differences in the order of the ips, as Mailgun does not
+// necessarily return them in the order they were assigned.
+func suppressIpsOrderDiff(k, old, new string, d *schema.ResourceData) bool {
+	o, n := d.GetChange("ips")
+	oldIps := interfaceToStringTab(o)
+	newIps := interfaceToStringTab(n)
+	if len(oldIps) != len(newIps) {
+		return false
+	}
+	sort.Strings(oldIps)
+	sort.Strings(newIps)
+	for i := range oldIps {
+		if oldIps[i] != newIps[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// suppressUnknownCredentialPassword ignores the configured password of an existing credential
+// when none is known in the state: Mailgun never returns passwords, so this happens after an import.
+func suppressUnknownCredentialPassword(k, old, new string, d *schema.ResourceData) bool {
+	if old != "" {
+		return false
+	}
+	loginKey := strings.TrimSuffix(k, "password") + "login"
+	oldLogin, newLogin := d.GetChange(loginKey)
+	return oldLogin.(string) != "" && shortLogin(oldLogin.(string)) == shortLogin(newLogin.(string))
+}
+
+// suppressLoginDomainDiff ignores whether a credential login is written with or without the domain.
+func suppressLoginDomainDiff(k, old, new string, d *schema.ResourceData) bool {
+	return old != "" && shortLogin(old) == shortLogin(new)
+}
+
+// suppressFooterWhitespaceDiff ignores differences in line endings and in leading and trailing
+// whitespace of the unsubscribe footers, which Mailgun does not store as sent.
+func suppressFooterWhitespaceDiff(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeFooter(old) == normalizeFooter(new)
+}
+
+func normalizeFooter(footer string) string {
+	footer = strings.Replace(footer, "\r\n", "\n", -1)
+	footer = strings.Replace(footer, "\r", "\n", -1)
+	lines := strings.Split(strings.TrimSpace(footer), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func getIps(ctx context.Context, mg *mailgun.MailgunImpl) ([]mailgun.IPAddress, error) {
 	var ipAddress []mailgun.IPAddress
-	log.Printf("[DEBUG] begin to fetch ips for %s",mg.Domain())
+	log.Printf("[DEBUG] begin to fetch ips for %s", mg.Domain())
 	err := resource.Retry(2*time.Minute, func() *resource.RetryError {
 		var err error
 		ipAddress, err = mg.ListDomainIPS(ctx)
+		if err != nil && ctx.Err() != nil {
+			return resource.NonRetryableError(err)
+		}
 		if err != nil {
-			log.Printf("[DEBUG] failed to fetch ips for %s",mg.Domain())
+			log.Printf("[DEBUG] failed to fetch ips for %s", mg.Domain())
 			return resource.RetryableError(err)
 		}
-		log.Printf("[DEBUG] managed to fetch ips for %s",mg.Domain())
+		log.Printf("[DEBUG] managed to fetch ips for %s", mg.Domain())
 
 		return nil
 	})
@@ -0,0 +1,91 @@
+package mailgun
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceMailgunDomainV0 is the schema of mailgun_domain before credentials became a set.
+// Only the types matter: it is used to read the state written with that version.
+func resourceMailgunDomainV0() *schema.Resource {
+	dnsRecord := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name":        {Type: schema.TypeString, Computed: true},
+			"priority":    {Type: schema.TypeString, Computed: true},
+			"record_type": {Type: schema.TypeString, Computed: true},
+			"valid":       {Type: schema.TypeString, Computed: true},
+			"value":       {Type: schema.TypeString, Computed: true},
+		},
+	}
+
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name":                 {Type: schema.TypeString, Required: true},
+			"spam_action":          {Type: schema.TypeString, Optional: true},
+			"smtp_password":        {Type: schema.TypeString, Optional: true},
+			"smtp_login":           {Type: schema.TypeString, Computed: true},
+			"wildcard":             {Type: schema.TypeBool, Optional: true},
+			"created_at":           {Type: schema.TypeString, Computed: true},
+			"state":                {Type: schema.TypeString, Computed: true},
+			"force_dkim_authority": {Type: schema.TypeBool, Optional: true},
+			"dkim_key_size":        {Type: schema.TypeInt, Optional: true},
+			"ips": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"credentials": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"created_at": {Type: schema.TypeString, Computed: true},
+						"login":      {Type: schema.TypeString, Required: true},
+						"password":   {Type: schema.TypeString, Optional: true},
+					},
+				},
+			},
+			"open_tracking_settings_active":             {Type: schema.TypeBool, Optional: true},
+			"click_tracking_settings_active":            {Type: schema.TypeBool, Optional: true},
+			"unsubscribe_tracking_settings_active":      {Type: schema.TypeBool, Optional: true},
+			"unsubscribe_tracking_settings_html_footer": {Type: schema.TypeString, Optional: true},
+			"unsubscribe_tracking_settings_text_footer": {Type: schema.TypeString, Optional: true},
+			"require_tls":       {Type: schema.TypeBool, Optional: true},
+			"skip_verification": {Type: schema.TypeBool, Optional: true},
+			"subaccount_id":     {Type: schema.TypeString, Optional: true},
+			"receiving_records": {Type: schema.TypeList, Computed: true, Elem: dnsRecord},
+			"sending_records":   {Type: schema.TypeList, Computed: true, Elem: dnsRecord},
+		},
+	}
+}
+
+// resourceMailgunDomainStateUpgradeV0 turns the credentials list into a set keyed by login.
+// Both are stored as arrays, so only credentials sharing a login need to be merged: logins are compared
+// without their domain, like the set does, the first credential is kept and its login is stored
+// without the domain, like ReadDomain does.
+func resourceMailgunDomainStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	credentials, ok := rawState["credentials"].([]interface{})
+	if !ok {
+		return rawState, nil
+	}
+
+	seen := make(map[string]bool, len(credentials))
+	unique := make([]interface{}, 0, len(credentials))
+	for _, c := range credentials {
+		credential, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		login, _ := credential["login"].(string)
+		login = shortLogin(login)
+		if seen[login] {
+			continue
+		}
+		seen[login] = true
+		credential["login"] = login
+		unique = append(unique, credential)
+	}
+
+	rawState["credentials"] = unique
+	return rawState, nil
+}
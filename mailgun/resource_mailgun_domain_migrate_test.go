@@ -0,0 +1,77 @@
+package mailgun
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResourceMailgunDomainStateUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{
+		"name": "domain.com",
+		"credentials": []interface{}{
+			map[string]interface{}{"login": "alice", "password": "first", "created_at": "1"},
+			map[string]interface{}{"login": "bob", "password": "second", "created_at": "2"},
+			map[string]interface{}{"login": "alice", "password": "third", "created_at": "3"},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"name": "domain.com",
+		"credentials": []interface{}{
+			map[string]interface{}{"login": "alice", "password": "first", "created_at": "1"},
+			map[string]interface{}{"login": "bob", "password": "second", "created_at": "2"},
+		},
+	}
+
+	actual, err := resourceMailgunDomainStateUpgradeV0(rawState, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected:\n%#v\ngot:\n%#v", expected, actual)
+	}
+}
+
+func TestResourceMailgunDomainStateUpgradeV0_loginDomain(t *testing.T) {
+	rawState := map[string]interface{}{
+		"name": "domain.com",
+		"credentials": []interface{}{
+			map[string]interface{}{"login": "alice@domain.com", "password": "first", "created_at": "1"},
+			map[string]interface{}{"login": "alice", "password": "second", "created_at": "2"},
+			map[string]interface{}{"login": "bob@domain.com", "password": "third", "created_at": "3"},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"name": "domain.com",
+		"credentials": []interface{}{
+			map[string]interface{}{"login": "alice", "password": "first", "created_at": "1"},
+			map[string]interface{}{"login": "bob", "password": "third", "created_at": "3"},
+		},
+	}
+
+	actual, err := resourceMailgunDomainStateUpgradeV0(rawState, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected:\n%#v\ngot:\n%#v", expected, actual)
+	}
+}
+
+func TestResourceMailgunDomainStateUpgradeV0_noCredentials(t *testing.T) {
+	rawState := map[string]interface{}{
+		"name": "domain.com",
+	}
+
+	actual, err := resourceMailgunDomainStateUpgradeV0(rawState, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, ok := actual["credentials"]; ok {
+		t.Fatalf("expected no credentials, got %#v", actual["credentials"])
+	}
+}
@@ -2,15 +2,24 @@ package mailgun
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mailgun/mailgun-go/v3"
+	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
-	"log"
 )
 
 type fullDomain struct {
@@ -21,10 +30,10 @@ type fullDomain struct {
 	credentials      []mailgun.Credential
 }
 
-func getFullDomain(mg *mailgun.MailgunImpl, domainName string) (*fullDomain, error) {
+func getFullDomain(config *Config, domainName string) (*fullDomain, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*120)
 	defer cancel()
-	mg = mailgun.NewMailgun(domainName, mg.APIKey())
+	mg := config.Client(domainName, "")
 
 	var domain fullDomain
 	var err error
@@ -54,7 +63,7 @@ func getFullDomain(mg *mailgun.MailgunImpl, domainName string) (*fullDomain, err
 
 	}
 	domain.ipAddress = ips
-	domain.credentials, err = ListCredentials(domainName, mg.APIKey())
+	domain.credentials, err = ListCredentials(mg)
 	if err != nil {
 		return nil, fmt.Errorf("Error Getting mailgun credentials for %s: Error: %s", domainName, err)
 	}
@@ -107,6 +116,45 @@ func TestAccMailgunDomain_withUpdate(t *testing.T) {
 	})
 }
 
+func TestAccMailgunDomain_manyCredentials(t *testing.T) {
+	var domain fullDomain
+
+	logins := make([]string, 12)
+	for i := range logins {
+		logins[i] = fmt.Sprintf("login%02d", i)
+	}
+	reversed := make([]string, len(logins))
+	for i, login := range logins {
+		reversed[len(logins)-1-i] = login
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccDomainCheckDestroy(&domain),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDomainConfigWithCredentials(logins),
+				Check: resource.ComposeTestCheckFunc(
+					testAccDomainCheckExists("mailgun_domain.exemple", &domain),
+					resource.TestCheckResourceAttr("mailgun_domain.exemple", "credentials.#", strconv.Itoa(len(logins))),
+				),
+			},
+			{
+				Config:             testAccDomainConfigWithCredentials(reversed),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+			{
+				Config: testAccDomainConfigWithCredentials(reversed[1:]),
+				Check: resource.ComposeTestCheckFunc(
+					testAccDomainCheckExists("mailgun_domain.exemple", &domain),
+					resource.TestCheckResourceAttr("mailgun_domain.exemple", "credentials.#", strconv.Itoa(len(logins)-1)),
+				),
+			},
+		},
+	})
+}
 
 func TestDomain_importBasic(t *testing.T) {
 	var domain fullDomain
@@ -119,7 +167,7 @@ func TestDomain_importBasic(t *testing.T) {
 			{
 				Config: interpolateTerraformTemplateDomain(testAccDomainConfig_import),
 				Check: resource.ComposeTestCheckFunc(
-					testAccDomainCheckExists("mailgun_domain.exemple",&domain),
+					testAccDomainCheckExists("mailgun_domain.exemple", &domain),
 				),
 			},
 			{
@@ -131,6 +179,91 @@ func TestDomain_importBasic(t *testing.T) {
 	})
 }
 
+func TestDomain_importDkimKeySize(t *testing.T) {
+	var domain fullDomain
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccDomainCheckDestroy(&domain),
+		Steps: []resource.TestStep{
+			{
+				Config: interpolateTerraformTemplateDomain(testAccDomainConfig_importDkimKeySize),
+				Check: resource.ComposeTestCheckFunc(
+					testAccDomainCheckExists("mailgun_domain.exemple", &domain),
+				),
+			},
+			{
+				ResourceName:            "mailgun_domain.exemple",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{fmt.Sprintf("credentials.%d.password", credentialHash(map[string]interface{}{"login": "aaaaaaa"}))},
+			},
+		},
+	})
+}
+
+func TestDkimKeySizeFromRecord(t *testing.T) {
+	for _, bits := range []int{1024, 2048} {
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		record := "k=rsa; p=" + base64.StdEncoding.EncodeToString(der)
+		size, err := dkimKeySizeFromRecord(record)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if size != bits {
+			t.Fatalf("expected a %d bits key, got %d", bits, size)
+		}
+	}
+
+	if _, err := dkimKeySizeFromRecord("v=spf1 include:mailgun.org ~all"); err == nil {
+		t.Fatal("expected an error for a record without public key")
+	}
+}
+
+// An imported domain whose guessed force_dkim_authority differs from the configuration is updated
+// in place, as the attribute is only taken into account by Mailgun when the domain is created.
+func TestDomain_importForceDkimAuthorityMismatch(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "sub.example.com",
+		Attributes: map[string]string{
+			"name":                 "sub.example.com",
+			"spam_action":          "disabled",
+			"dkim_key_size":        "1024",
+			"wildcard":             "false",
+			"force_dkim_authority": "false",
+			"deletion_protection":  "false",
+		},
+	}
+	rawConfig, err := config.NewRawConfig(map[string]interface{}{
+		"name":                 "sub.example.com",
+		"force_dkim_authority": true,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	diff, err := resourceMailgunDomain().Diff(state, terraform.NewResourceConfig(rawConfig), &Config{APIKey: "key"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Fatalf("expected the domain not to be replaced, got %+v", diff)
+	}
+	attr := diff.Attributes["force_dkim_authority"]
+	if attr == nil || attr.Old != "false" || attr.New != "true" {
+		t.Errorf("expected an in place update of force_dkim_authority, got %+v", attr)
+	}
+}
+
 func testAccDomainCheckExists(rn string, domain *fullDomain) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[rn]
@@ -142,11 +275,11 @@ func testAccDomainCheckExists(rn string, domain *fullDomain) resource.TestCheckF
 			return fmt.Errorf("domainID not set")
 		}
 
-		mg := testAccProvider.Meta().(*mailgun.MailgunImpl)
+		config := testAccProvider.Meta().(*Config)
 
 		domainId := rs.Primary.ID
 
-		gotDomain, err := getFullDomain(mg, domainId)
+		gotDomain, err := getFullDomain(config, domainId)
 		if err != nil {
 			return fmt.Errorf("error getting domain: %s", err)
 		}
@@ -208,20 +341,20 @@ func testAccDomainCheckAttributes(rn string, domain *fullDomain) resource.TestCh
 
 func testAccDomainCheckDestroy(domain *fullDomain) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		mg := testAccProvider.Meta().(*mailgun.MailgunImpl)
+		mg := testAccProvider.Meta().(*Config).Client(domain.domainResponse.Domain.Name, "")
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 		defer cancel()
 
-		log.Printf("[DEBUG] try to fetch destroyed domain %s",mg.Domain())
+		log.Printf("[DEBUG] try to fetch destroyed domain %s", mg.Domain())
 
 		return resource.Retry(1*time.Minute, func() *resource.RetryError {
 			_, err := mg.GetDomain(ctx, domain.domainResponse.Domain.Name)
 			if err == nil {
-				log.Printf("[DEBUG] managed to fetch destroyed domain %s",mg.Domain())
+				log.Printf("[DEBUG] managed to fetch destroyed domain %s", mg.Domain())
 				return resource.RetryableError(err)
 			}
 
-			log.Printf("[DEBUG] failed to fetch destroyed domain %s",mg.Domain())
+			log.Printf("[DEBUG] failed to fetch destroyed domain %s", mg.Domain())
 
 			return nil
 		})
@@ -238,6 +371,23 @@ func interpolateTerraformTemplateDomain(template string) string {
 	return fmt.Sprintf(template, domainName)
 }
 
+func testAccDomainConfigWithCredentials(logins []string) string {
+	credentials := ""
+	for _, login := range logins {
+		credentials += fmt.Sprintf(`
+	credentials {
+		login    = "%s"
+		password = "adfshfjqdskjhgfksdgfkqgfk"
+	}`, login)
+	}
+	return fmt.Sprintf(`
+resource "mailgun_domain" "exemple" {
+	name = "%s"
+%s
+}
+`, interpolateTerraformTemplateDomain("%s"), credentials)
+}
+
 const testAccDomainConfig_basic = `
 resource "mailgun_domain" "exemple" {
 	name="%s"
@@ -264,3 +414,227 @@ resource "mailgun_domain" "exemple" {
 	name = "%s"
 }
 `
+
+const testAccDomainConfig_importDkimKeySize = `
+resource "mailgun_domain" "exemple" {
+	name = "%s"
+	dkim_key_size = 2048
+	unsubscribe_tracking_settings_active = true
+	credentials {
+		login = "aaaaaaa"
+		password = "adfshfjqdskjhgfksdgfkqgfk"
+	}
+}
+`
+
+func TestSuppressFooterWhitespaceDiff(t *testing.T) {
+	equivalent := [][2]string{
+		{"\n<br>\n<p><a href=\"%unsubscribe_url%\">unsubscribe</a></p>\n", "<br>\n<p><a href=\"%unsubscribe_url%\">unsubscribe</a></p>"},
+		{"\r\n\r\nTo unsubscribe click: <%unsubscribe_url%>\r\n\r\n", "\n\nTo unsubscribe click: <%unsubscribe_url%>\n\n"},
+		{"line one  \r\nline two\t\n", "line one\nline two"},
+		{"line one\rline two", "line one\nline two"},
+	}
+	for _, c := range equivalent {
+		if !suppressFooterWhitespaceDiff("unsubscribe_tracking_settings_text_footer", c[0], c[1], nil) {
+			t.Errorf("expected %q and %q to be equivalent", c[0], c[1])
+		}
+	}
+
+	different := [][2]string{
+		{"line one\nline two", "line one line two"},
+		{"  indented", "indented\n\nmore"},
+		{"unsubscribe", "Unsubscribe"},
+	}
+	for _, c := range different {
+		if suppressFooterWhitespaceDiff("unsubscribe_tracking_settings_text_footer", c[0], c[1], nil) {
+			t.Errorf("expected %q and %q to be different", c[0], c[1])
+		}
+	}
+}
+
+func TestDomainDeletionProtection_replace(t *testing.T) {
+	for _, protected := range []bool{true, false} {
+		state := &terraform.InstanceState{
+			ID: "example.com",
+			Attributes: map[string]string{
+				"name":                "example.com",
+				"spam_action":         "disabled",
+				"dkim_key_size":       "1024",
+				"wildcard":            "false",
+				"deletion_protection": strconv.FormatBool(protected),
+			},
+		}
+		rawConfig, err := config.NewRawConfig(map[string]interface{}{
+			"name":                "example.com",
+			"dkim_key_size":       2048,
+			"deletion_protection": protected,
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		diff, err := resourceMailgunDomain().Diff(state, terraform.NewResourceConfig(rawConfig), &Config{APIKey: "key"})
+		if protected {
+			if err == nil || !strings.Contains(err.Error(), "dkim_key_size") {
+				t.Errorf("expected the replacement of a protected domain to fail on dkim_key_size, got %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !diff.RequiresNew() {
+			t.Error("expected the unprotected domain to be replaced")
+		}
+	}
+}
+
+func TestDomainDeletionProtection_update(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "example.com",
+		Attributes: map[string]string{
+			"name":                "example.com",
+			"spam_action":         "disabled",
+			"dkim_key_size":       "1024",
+			"wildcard":            "false",
+			"require_tls":         "false",
+			"deletion_protection": "true",
+		},
+	}
+	rawConfig, err := config.NewRawConfig(map[string]interface{}{
+		"name":                "example.com",
+		"require_tls":         true,
+		"deletion_protection": false,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	diff, err := resourceMailgunDomain().Diff(state, terraform.NewResourceConfig(rawConfig), &Config{APIKey: "key"})
+	if err != nil {
+		t.Fatalf("expected in place updates of a protected domain to be planned, got %s", err)
+	}
+	if diff.RequiresNew() || diff.Attributes["deletion_protection"] == nil {
+		t.Errorf("expected an in place update disabling the protection, got %+v", diff)
+	}
+}
+
+func TestDomainDeletionProtection_destroy(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceMailgunDomain().Schema, map[string]interface{}{
+		"name":                "example.com",
+		"deletion_protection": true,
+	})
+	d.SetId("example.com")
+
+	err := DeleteDomain(d, &Config{APIKey: "key"})
+	if err == nil || !strings.Contains(err.Error(), "deletion_protection") {
+		t.Errorf("expected the deletion of a protected domain to be refused, got %v", err)
+	}
+}
+
+// newFakeDomainServer serves the requests of ReadDomain for example.com, each one taking latency.
+// The requests whose path ends with failing get an error.
+func newFakeDomainServer(latency time.Duration, failing string) *httptest.Server {
+	created := `"created_at": "Sat, 19 Oct 2019 16:00:00 UTC"`
+	responses := map[string]string{
+		"/v3/domains/example.com": `{"domain": {"name": "example.com", "spam_action": "disabled", "state": "active", ` + created + `},
+			"receiving_dns_records": [], "sending_dns_records": []}`,
+		"/v3/domains/example.com/connection":  `{"connection": {"require_tls": true, "skip_verification": false}}`,
+		"/v3/domains/example.com/tracking":    `{"tracking": {"open": {"active": true}, "click": {"active": false}, "unsubscribe": {"active": false}}}`,
+		"/v3/domains/example.com/ips":         `{"total_count": 1, "items": ["192.168.0.1"]}`,
+		"/v3/domains/example.com/credentials": `{"total_count": 1, "items": [{"login": "postmaster@example.com", ` + created + `}]}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(latency)
+		if failing != "" && strings.HasSuffix(r.URL.Path, failing) {
+			http.Error(w, `{"message": "failure"}`, http.StatusBadRequest)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("skip") != "" {
+			response = `{"total_count": 1, "items": []}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+}
+
+func TestReadDomain_fakeServer(t *testing.T) {
+	server := newFakeDomainServer(0, "")
+	defer server.Close()
+
+	d := resourceMailgunDomain().TestResourceData()
+	d.SetId("example.com")
+	if err := ReadDomain(d, &Config{APIKey: "key", apiBase: server.URL + "/v3"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"state":                         "active",
+		"require_tls":                   true,
+		"open_tracking_settings_active": true,
+		"ips.0":                         "192.168.0.1",
+		"credentials.#":                 1,
+	}
+	for key, value := range expected {
+		if got := d.Get(key); got != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, got)
+		}
+	}
+
+	// Without a configuration, as after an import, the login is stored without the domain.
+	login := credentialHash(map[string]interface{}{"login": "postmaster"})
+	if got := d.Get(fmt.Sprintf("credentials.%d.login", login)); got != "postmaster" {
+		t.Errorf("expected the credential login postmaster, got %v", got)
+	}
+}
+
+func TestCredentialHash(t *testing.T) {
+	short := credentialHash(map[string]interface{}{"login": "aaaaaaa"})
+	full := credentialHash(map[string]interface{}{"login": "aaaaaaa@domain.com"})
+	if short != full {
+		t.Errorf("expected logins with and without the domain to hash alike, got %d and %d", short, full)
+	}
+	if other := credentialHash(map[string]interface{}{"login": "bbbbbbb@domain.com"}); other == short {
+		t.Errorf("expected different logins to hash differently")
+	}
+}
+
+func TestReadDomain_fakeServerError(t *testing.T) {
+	server := newFakeDomainServer(0, "/tracking")
+	defer server.Close()
+
+	d := resourceMailgunDomain().TestResourceData()
+	d.SetId("example.com")
+	err := ReadDomain(d, &Config{APIKey: "key", apiBase: server.URL + "/v3"})
+	if err == nil || !strings.HasPrefix(err.Error(), "Error reading the tracking settings of mailgun_domain example.com") {
+		t.Errorf("expected the tracking error, got %v", err)
+	}
+}
+
+// BenchmarkReadDomain reads a domain from a local server answering each request in 10ms,
+// sending the requests one at a time then concurrently.
+func BenchmarkReadDomain(b *testing.B) {
+	server := newFakeDomainServer(10*time.Millisecond, "")
+	defer server.Close()
+
+	defer func(concurrency int) { domainReadConcurrency = concurrency }(domainReadConcurrency)
+	for _, concurrency := range []int{1, domainReadConcurrency} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			domainReadConcurrency = concurrency
+			config := &Config{APIKey: "key", apiBase: server.URL + "/v3"}
+			for i := 0; i < b.N; i++ {
+				d := resourceMailgunDomain().TestResourceData()
+				d.SetId("example.com")
+				if err := ReadDomain(d, config); err != nil {
+					b.Fatalf("err: %s", err)
+				}
+			}
+		})
+	}
+}
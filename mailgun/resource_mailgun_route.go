@@ -18,6 +18,7 @@ func resourceMailgunRoute() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: customizeRouteDiff,
 
 		Schema: map[string]*schema.Schema{
 			"route_id": &schema.Schema{
@@ -35,8 +36,40 @@ func resourceMailgunRoute() *schema.Resource {
 			},
 
 			"expression": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ConflictsWith:    []string{"match_recipient", "match_header", "catch_all"},
+				DiffSuppressFunc: suppressRouteExpressionDiff,
+			},
+
+			"match_recipient": &schema.Schema{
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+			},
+
+			"match_header": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"regex": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+
+			"catch_all": &schema.Schema{
+				Type:          schema.TypeBool,
+				Optional:      true,
+				ConflictsWith: []string{"match_recipient", "match_header"},
 			},
 
 			"description": &schema.Schema{
@@ -45,20 +78,57 @@ func resourceMailgunRoute() *schema.Resource {
 			},
 
 			"actions": &schema.Schema{
+				Type:             schema.TypeList,
+				Optional:         true,
+				Computed:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				ConflictsWith:    []string{"forward", "store", "stop"},
+				DiffSuppressFunc: suppressRouteActionsDiff,
+			},
+
+			"forward": &schema.Schema{
 				Type:     schema.TypeList,
-				Required: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"destination": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
 			},
+
+			"store": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"notify": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+
+			"stop": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			"subaccount_id": subaccountIDSchema(),
 		},
 	}
 }
 
 func CreateRoute(d *schema.ResourceData, meta interface{}) error {
-	mg := meta.(*mailgun.MailgunImpl)
+	mg := resourceClient(d, meta, "")
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
-	log.Printf("[DEBUG] creating  mailgun route: %s", d.Id())
+	log.Printf("[DEBUG] creating  mailgun route: %q with priority %d", d.Get("description").(string), d.Get("priority").(int))
 
 	creationResponse, err := mg.CreateRoute(ctx, mailgun.Route{
 		Priority:    d.Get("priority").(int),
@@ -68,7 +138,7 @@ func CreateRoute(d *schema.ResourceData, meta interface{}) error {
 	})
 
 	if err != nil {
-		return fmt.Errorf("Error creating mailgun route: %s", err.Error())
+		return newAPIError("creating", "mailgun_route", err)
 	}
 
 	d.SetId(creationResponse.Id)
@@ -76,7 +146,7 @@ func CreateRoute(d *schema.ResourceData, meta interface{}) error {
 }
 
 func UpdateRoute(d *schema.ResourceData, meta interface{}) error {
-	mg := meta.(*mailgun.MailgunImpl)
+	mg := resourceClient(d, meta, "")
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
@@ -90,14 +160,14 @@ func UpdateRoute(d *schema.ResourceData, meta interface{}) error {
 	})
 
 	if err != nil {
-		return fmt.Errorf("Error updating mailgun route: %s", err.Error())
+		return newAPIError("updating", "mailgun_route "+d.Id(), err)
 	}
 
 	return ReadRoute(d, meta)
 }
 
 func DeleteRoute(d *schema.ResourceData, meta interface{}) error {
-	mg := meta.(*mailgun.MailgunImpl)
+	mg := resourceClient(d, meta, "")
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
@@ -105,24 +175,41 @@ func DeleteRoute(d *schema.ResourceData, meta interface{}) error {
 
 	err := mg.DeleteRoute(ctx, d.Id())
 
-	return err
+	return newAPIError("deleting", "mailgun_route "+d.Id(), err)
 }
 
 func ReadRoute(d *schema.ResourceData, meta interface{}) error {
-	mg := meta.(*mailgun.MailgunImpl)
+	mg := resourceClient(d, meta, "")
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
 	route, err := mg.GetRoute(ctx, d.Id())
 
 	if err != nil {
-		return fmt.Errorf("Error Getting mailgun route Details for %s: Error: %s", d.Id(), err)
+		return newAPIError("reading", "mailgun_route "+d.Id(), err)
 	}
 
 	d.Set("priority", route.Priority)
 	d.Set("description", route.Description)
 	d.Set("expression", route.Expression)
 	d.Set("actions", route.Actions)
+
+	if _, ok := structuredRouteExpression(d); ok {
+		expression, err := parseRouteExpression(route.Expression)
+		if err != nil {
+			log.Printf("[WARN] mailgun route %s expression has no structured form: %s", d.Id(), err)
+		}
+		setStructuredRouteExpression(d, expression)
+	}
+
+	if _, ok := structuredRouteActions(d); ok {
+		actions, err := parseRouteActions(route.Actions)
+		if err != nil {
+			log.Printf("[WARN] mailgun route %s actions have no structured form: %s", d.Id(), err)
+		}
+		setStructuredRouteActions(d, actions)
+	}
+
 	d.Set("created_at", route.CreatedAt.String())
 	d.Set("route_id", route.Id)
 
@@ -130,3 +217,100 @@ func ReadRoute(d *schema.ResourceData, meta interface{}) error {
 
 	return nil
 }
+
+// customizeRouteDiff renders the structured filters and actions into the expression and actions
+// sent to Mailgun, so that the plan shows them.
+func customizeRouteDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if expression, ok := structuredRouteExpression(d); ok {
+		if !d.NewValueKnown("match_recipient") || !d.NewValueKnown("match_header") {
+			d.SetNewComputed("expression")
+		} else if rendered := expression.String(); normalizeRouteCode(rendered) != normalizeRouteCode(d.Get("expression").(string)) {
+			d.SetNew("expression", rendered)
+		}
+	} else if d.NewValueKnown("expression") && d.Get("expression").(string) == "" {
+		return fmt.Errorf("one of expression, match_recipient, match_header or catch_all must be set")
+	}
+
+	if actions, ok := structuredRouteActions(d); ok {
+		if !d.NewValueKnown("forward") || !d.NewValueKnown("store") {
+			d.SetNewComputed("actions")
+		} else if rendered := actions.Strings(); !routeActionsEquivalent(rendered, interfaceToStringTab(d.Get("actions"))) {
+			d.SetNew("actions", rendered)
+		}
+	} else if d.NewValueKnown("actions") && len(d.Get("actions").([]interface{})) == 0 {
+		return fmt.Errorf("one of actions, forward, store or stop must be set")
+	}
+
+	return nil
+}
+
+// routeGetter is implemented by both schema.ResourceData and schema.ResourceDiff.
+type routeGetter interface {
+	Get(key string) interface{}
+}
+
+func structuredRouteExpression(d routeGetter) (routeExpression, bool) {
+	var e routeExpression
+	e.matchRecipient = d.Get("match_recipient").(string)
+	if headers := d.Get("match_header").([]interface{}); len(headers) > 0 && headers[0] != nil {
+		header := headers[0].(map[string]interface{})
+		e.hasHeader = true
+		e.headerName = header["name"].(string)
+		e.headerRegex = header["regex"].(string)
+	}
+	e.catchAll = d.Get("catch_all").(bool)
+	return e, e.matchRecipient != "" || e.hasHeader || e.catchAll
+}
+
+func structuredRouteActions(d routeGetter) (routeActions, bool) {
+	var a routeActions
+	for _, f := range d.Get("forward").([]interface{}) {
+		if f != nil {
+			a.forward = append(a.forward, f.(map[string]interface{})["destination"].(string))
+		}
+	}
+	if stores := d.Get("store").([]interface{}); len(stores) > 0 {
+		a.store = true
+		if stores[0] != nil {
+			a.storeNotify = stores[0].(map[string]interface{})["notify"].(string)
+		}
+	}
+	a.stop = d.Get("stop").(bool)
+	return a, len(a.forward) > 0 || a.store || a.stop
+}
+
+func setStructuredRouteExpression(d *schema.ResourceData, e routeExpression) {
+	d.Set("match_recipient", e.matchRecipient)
+	var headers []map[string]interface{}
+	if e.hasHeader {
+		headers = append(headers, map[string]interface{}{"name": e.headerName, "regex": e.headerRegex})
+	}
+	d.Set("match_header", headers)
+	d.Set("catch_all", e.catchAll)
+}
+
+func setStructuredRouteActions(d *schema.ResourceData, a routeActions) {
+	forward := make([]map[string]interface{}, len(a.forward))
+	for i, destination := range a.forward {
+		forward[i] = map[string]interface{}{"destination": destination}
+	}
+	d.Set("forward", forward)
+	var stores []map[string]interface{}
+	if a.store {
+		stores = append(stores, map[string]interface{}{"notify": a.storeNotify})
+	}
+	d.Set("store", stores)
+	d.Set("stop", a.stop)
+}
+
+func routeActionsEquivalent(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if normalizeRouteCode(a[i]) != normalizeRouteCode(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,414 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/mailgun/mailgun-go/v3"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+func resourceMailgunRouteSet() *schema.Resource {
+	return &schema.Resource{
+		Create:        CreateRouteSet,
+		Update:        UpdateRouteSet,
+		Delete:        DeleteRouteSet,
+		Read:          ReadRouteSet,
+		CustomizeDiff: customizeRouteSetDiff,
+
+		Schema: map[string]*schema.Schema{
+			"priority_start": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"priority_end": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"route": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"description": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"expression": &schema.Schema{
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressRouteExpressionDiff,
+						},
+						"actions": &schema.Schema{
+							Type:             schema.TypeList,
+							Required:         true,
+							MinItems:         1,
+							Elem:             &schema.Schema{Type: schema.TypeString},
+							DiffSuppressFunc: suppressRouteActionsDiff,
+						},
+					},
+				},
+			},
+
+			"adopt_existing": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"route_ids": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"priorities": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+
+			"subaccount_id": subaccountIDSchema(),
+		},
+	}
+}
+
+func CreateRouteSet(d *schema.ResourceData, meta interface{}) error {
+	d.SetId(resource.PrefixedUniqueId("route-set-"))
+
+	log.Printf("[DEBUG] creating mailgun route set: %s", d.Id())
+
+	if err := applyRouteSet(d, meta); err != nil {
+		return err
+	}
+
+	return ReadRouteSet(d, meta)
+}
+
+func UpdateRouteSet(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] updating mailgun route set: %s", d.Id())
+
+	if err := applyRouteSet(d, meta); err != nil {
+		return err
+	}
+
+	return ReadRouteSet(d, meta)
+}
+
+func DeleteRouteSet(d *schema.ResourceData, meta interface{}) error {
+	mg := resourceClient(d, meta, "")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+
+	log.Printf("[DEBUG] Deleting mailgun route set: %s", d.Id())
+
+	for _, id := range interfaceToStringTab(d.Get("route_ids")) {
+		err := mg.DeleteRoute(ctx, id)
+		if err != nil && mailgun.GetStatusFromErr(err) != http.StatusNotFound {
+			return newAPIError("deleting the route "+id+" of", "mailgun_route_set "+d.Id(), err)
+		}
+	}
+
+	return nil
+}
+
+func ReadRouteSet(d *schema.ResourceData, meta interface{}) error {
+	mg := resourceClient(d, meta, "")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+
+	var routes []mailgun.Route
+	for _, id := range interfaceToStringTab(d.Get("route_ids")) {
+		route, err := mg.GetRoute(ctx, id)
+		if mailgun.GetStatusFromErr(err) == http.StatusNotFound {
+			log.Printf("[WARN] mailgun route %s of route set %s not found, removing from state", id, d.Id())
+			continue
+		}
+		if err != nil {
+			return newAPIError("reading the route "+id+" of", "mailgun_route_set "+d.Id(), err)
+		}
+		routes = append(routes, route)
+	}
+
+	setRouteSetState(d, routes)
+	return nil
+}
+
+// customizeRouteSetDiff checks the routes fit in the priority band and plans the priority of each route.
+func customizeRouteSetDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("route") || !d.NewValueKnown("priority_start") || !d.NewValueKnown("priority_end") {
+		d.SetNewComputed("route_ids")
+		d.SetNewComputed("priorities")
+		return nil
+	}
+
+	start, end := d.Get("priority_start").(int), d.Get("priority_end").(int)
+	if end < start {
+		return fmt.Errorf("priority_end (%d) must not be lower than priority_start (%d)", end, start)
+	}
+
+	desired := routeSetRoutes(d.Get("route").([]interface{}), start)
+	if len(desired) > end-start+1 {
+		return fmt.Errorf("%d routes do not fit in the priority band %d-%d", len(desired), start, end)
+	}
+	seen := make(map[string]bool)
+	for _, r := range desired {
+		if seen[r.Description] {
+			return fmt.Errorf("route descriptions must be unique within a route set, %q is used twice", r.Description)
+		}
+		seen[r.Description] = true
+	}
+
+	priorities := make([]interface{}, len(desired))
+	for i, r := range desired {
+		priorities[i] = r.Priority
+	}
+	if !intListsEqual(priorities, d.Get("priorities").([]interface{})) {
+		d.SetNew("priorities", priorities)
+	}
+
+	old, _ := d.GetChange("route")
+	current := routeSetRoutes(old.([]interface{}), 0)
+	if len(current) != len(desired) {
+		d.SetNewComputed("route_ids")
+		return nil
+	}
+	for i := range desired {
+		if current[i].Description != desired[i].Description {
+			d.SetNewComputed("route_ids")
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// applyRouteSet brings the routes of Mailgun to the planned route set. The routes are created or
+// updated from the last one to the first one, so that each route is put at its final priority
+// behind routes which already are, and the routes removed from the set are deleted last, so that
+// a replaced route keeps matching until its replacement exists.
+// When the apply fails midway, the routes which exist are kept in the state.
+func applyRouteSet(d *schema.ResourceData, meta interface{}) error {
+	mg := resourceClient(d, meta, "")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+
+	oldRoutes, _ := d.GetChange("route")
+	oldIDs, _ := d.GetChange("route_ids")
+	oldPriorities, _ := d.GetChange("priorities")
+	current := routeSetRoutes(oldRoutes.([]interface{}), 0)
+	for i, id := range interfaceToStringTab(oldIDs) {
+		if i < len(current) {
+			current[i].Id = id
+		}
+	}
+	for i, p := range oldPriorities.([]interface{}) {
+		if i < len(current) {
+			current[i].Priority = p.(int)
+		}
+	}
+
+	desired := routeSetRoutes(d.Get("route").([]interface{}), d.Get("priority_start").(int))
+
+	if d.Get("adopt_existing").(bool) {
+		adopted, err := adoptRoutes(ctx, mg, current, desired)
+		if err != nil {
+			return newAPIError("listing the routes to adopt for", "mailgun_route_set "+d.Id(), err)
+		}
+		current = append(current, adopted...)
+	}
+
+	live := make(map[string]mailgun.Route)
+	for _, r := range current {
+		if r.Id != "" {
+			live[r.Id] = r
+		}
+	}
+	defer func() {
+		routes := make([]mailgun.Route, 0, len(live))
+		for _, r := range live {
+			routes = append(routes, r)
+		}
+		setRouteSetState(d, routes)
+	}()
+
+	deletes, writes := planRouteSet(current, desired)
+
+	for _, r := range writes {
+		if r.Id == "" {
+			log.Printf("[DEBUG] creating mailgun route %q of route set %s at priority %d", r.Description, d.Id(), r.Priority)
+			created, err := mg.CreateRoute(ctx, r)
+			if err != nil {
+				return newAPIError(fmt.Sprintf("creating the route %q of", r.Description), "mailgun_route_set "+d.Id(), err)
+			}
+			r.Id = created.Id
+		} else {
+			log.Printf("[DEBUG] updating mailgun route %s of route set %s at priority %d", r.Id, d.Id(), r.Priority)
+			if _, err := mg.UpdateRoute(ctx, r.Id, r); err != nil {
+				return newAPIError("updating the route "+r.Id+" of", "mailgun_route_set "+d.Id(), err)
+			}
+		}
+		live[r.Id] = r
+	}
+
+	for _, id := range deletes {
+		log.Printf("[DEBUG] deleting mailgun route %s of route set %s", id, d.Id())
+		err := mg.DeleteRoute(ctx, id)
+		if err != nil && mailgun.GetStatusFromErr(err) != http.StatusNotFound {
+			return newAPIError("deleting the route "+id+" of", "mailgun_route_set "+d.Id(), err)
+		}
+		delete(live, id)
+	}
+
+	return nil
+}
+
+// planRouteSet returns the ids of the current routes to delete and the routes to write, in the
+// order they must be written. Current routes are matched with the desired ones by description,
+// and are only written when they differ.
+func planRouteSet(current, desired []mailgun.Route) ([]string, []mailgun.Route) {
+	wanted := make(map[string]bool)
+	for _, r := range desired {
+		wanted[r.Description] = true
+	}
+
+	matched := make(map[string]mailgun.Route)
+	var deletes []string
+	for _, r := range current {
+		if _, ok := matched[r.Description]; ok || !wanted[r.Description] {
+			deletes = append(deletes, r.Id)
+			continue
+		}
+		matched[r.Description] = r
+	}
+
+	var writes []mailgun.Route
+	for i := len(desired) - 1; i >= 0; i-- {
+		r := desired[i]
+		if existing, ok := matched[r.Description]; ok {
+			r.Id = existing.Id
+			if existing.Priority == r.Priority &&
+				normalizeRouteCode(existing.Expression) == normalizeRouteCode(r.Expression) &&
+				routeActionsEquivalent(existing.Actions, r.Actions) {
+				continue
+			}
+		}
+		writes = append(writes, r)
+	}
+	return deletes, writes
+}
+
+// adoptRoutes finds the routes of the account matching by description the desired routes
+// which are not managed yet.
+func adoptRoutes(ctx context.Context, mg *mailgun.MailgunImpl, current, desired []mailgun.Route) ([]mailgun.Route, error) {
+	missing := make(map[string]bool)
+	for _, r := range desired {
+		missing[r.Description] = true
+	}
+	managed := make(map[string]bool)
+	for _, r := range current {
+		delete(missing, r.Description)
+		managed[r.Id] = true
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	var adopted []mailgun.Route
+	adoptedDescriptions := make(map[string]bool)
+	it := mg.ListRoutes(nil)
+	var page []mailgun.Route
+	for it.Next(ctx, &page) {
+		for _, r := range page {
+			if managed[r.Id] {
+				continue
+			}
+			if missing[r.Description] {
+				log.Printf("[DEBUG] adopting mailgun route %s: %s", r.Id, r.Description)
+				delete(missing, r.Description)
+				adoptedDescriptions[r.Description] = true
+				adopted = append(adopted, r)
+			} else if adoptedDescriptions[r.Description] {
+				log.Printf("[WARN] mailgun route %s has the description of an adopted route, it is left alone: %s", r.Id, r.Description)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return adopted, nil
+}
+
+// routeSetRoutes reads the route blocks of a route set, giving them consecutive priorities from start.
+func routeSetRoutes(blocks []interface{}, start int) []mailgun.Route {
+	routes := make([]mailgun.Route, 0, len(blocks))
+	for i, b := range blocks {
+		if b == nil {
+			continue
+		}
+		block := b.(map[string]interface{})
+		routes = append(routes, mailgun.Route{
+			Priority:    start + i,
+			Description: block["description"].(string),
+			Expression:  block["expression"].(string),
+			Actions:     interfaceToStringTab(block["actions"]),
+		})
+	}
+	return routes
+}
+
+// setRouteSetState stores routes in the state, ordered by priority as Mailgun evaluates them.
+// Routes of the same priority are kept in the order of the configuration.
+func setRouteSetState(d *schema.ResourceData, routes []mailgun.Route) {
+	order := make(map[string]int)
+	for i, r := range routeSetRoutes(d.Get("route").([]interface{}), 0) {
+		order[r.Description] = i
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Priority != routes[j].Priority {
+			return routes[i].Priority < routes[j].Priority
+		}
+		if order[routes[i].Description] != order[routes[j].Description] {
+			return order[routes[i].Description] < order[routes[j].Description]
+		}
+		return routes[i].Id < routes[j].Id
+	})
+
+	blocks := make([]map[string]interface{}, len(routes))
+	ids := make([]string, len(routes))
+	priorities := make([]int, len(routes))
+	for i, r := range routes {
+		blocks[i] = map[string]interface{}{
+			"description": r.Description,
+			"expression":  r.Expression,
+			"actions":     r.Actions,
+		}
+		ids[i] = r.Id
+		priorities[i] = r.Priority
+	}
+	d.Set("route", blocks)
+	d.Set("route_ids", ids)
+	d.Set("priorities", priorities)
+}
+
+func intListsEqual(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package mailgun
+
+import (
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mailgun/mailgun-go/v3"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanRouteSet(t *testing.T) {
+	current := []mailgun.Route{
+		{Id: "a", Priority: 10, Description: "A", Expression: `match_recipient("a@example.com")`, Actions: []string{"stop()"}},
+		{Id: "b", Priority: 11, Description: "B", Expression: `match_recipient("b@example.com")`, Actions: []string{"stop()"}},
+		{Id: "c", Priority: 12, Description: "C", Expression: `match_recipient("c@example.com")`, Actions: []string{"stop()"}},
+	}
+	desired := []mailgun.Route{
+		{Priority: 10, Description: "A", Expression: `match_recipient('a@example.com')`, Actions: []string{"stop()"}},
+		{Priority: 11, Description: "X", Expression: `match_recipient("x@example.com")`, Actions: []string{"stop()"}},
+		{Priority: 12, Description: "B", Expression: `match_recipient("b@example.com")`, Actions: []string{"stop()"}},
+	}
+
+	deletes, writes := planRouteSet(current, desired)
+
+	if !reflect.DeepEqual(deletes, []string{"c"}) {
+		t.Errorf("expected c to be deleted, got %v", deletes)
+	}
+	var order []string
+	for _, r := range writes {
+		order = append(order, r.Id+":"+r.Description)
+	}
+	if !reflect.DeepEqual(order, []string{"b:B", ":X"}) {
+		t.Errorf("expected B to be moved then X to be created, got %v", order)
+	}
+	if writes[0].Priority != 12 || writes[1].Priority != 11 {
+		t.Errorf("unexpected priorities %d and %d", writes[0].Priority, writes[1].Priority)
+	}
+}
+
+func TestPlanRouteSet_duplicateDescriptions(t *testing.T) {
+	current := []mailgun.Route{
+		{Id: "a1", Priority: 0, Description: "A", Expression: "catch_all()", Actions: []string{"stop()"}},
+		{Id: "a2", Priority: 0, Description: "A", Expression: "catch_all()", Actions: []string{"stop()"}},
+	}
+	desired := []mailgun.Route{
+		{Priority: 0, Description: "A", Expression: "catch_all()", Actions: []string{"stop()"}},
+	}
+
+	deletes, writes := planRouteSet(current, desired)
+
+	if !reflect.DeepEqual(deletes, []string{"a2"}) || len(writes) != 0 {
+		t.Errorf("expected only the duplicate to be deleted, got %v and %v", deletes, writes)
+	}
+}
+
+func TestCustomizeRouteSetDiff(t *testing.T) {
+	route := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"expression":  "catch_all()",
+			"actions":     []interface{}{"stop()"},
+		}
+	}
+
+	cases := []struct {
+		routes []interface{}
+		end    int
+		err    string
+	}{
+		{routes: []interface{}{route("A"), route("B")}, end: 11},
+		{routes: []interface{}{route("A"), route("B"), route("C")}, end: 11, err: "do not fit"},
+		{routes: []interface{}{route("A"), route("A")}, end: 11, err: "used twice"},
+		{routes: []interface{}{route("A")}, end: 9, err: "must not be lower"},
+	}
+
+	for i, c := range cases {
+		rawConfig, err := config.NewRawConfig(map[string]interface{}{
+			"priority_start": 10,
+			"priority_end":   c.end,
+			"route":          c.routes,
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		diff, err := resourceMailgunRouteSet().Diff(nil, terraform.NewResourceConfig(rawConfig), &Config{APIKey: "key"})
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%d: expected an error containing %q, got %v", i, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%d: err: %s", i, err)
+		}
+
+		if diff.Attributes["priorities.0"].New != "10" || diff.Attributes["priorities.1"].New != "11" {
+			t.Errorf("%d: unexpected priorities %+v %+v", i, diff.Attributes["priorities.0"], diff.Attributes["priorities.1"])
+		}
+		if !diff.Attributes["route_ids.#"].NewComputed {
+			t.Errorf("%d: expected route_ids to be computed", i)
+		}
+	}
+}
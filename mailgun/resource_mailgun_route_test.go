@@ -57,6 +57,30 @@ func TestAccMailgunRoute_withUpdate(t *testing.T) {
 	})
 }
 
+func TestAccMailgunRoute_structured(t *testing.T) {
+	var route mailgun.Route
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccRouteCheckDestroy(&route),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRouteConfig_structured,
+				Check: resource.ComposeTestCheckFunc(
+					testAccRouteCheckExists("mailgun_route.exemple", &route),
+					testAccRouteCheckAttributes("mailgun_route.exemple", &route),
+					resource.TestCheckResourceAttr("mailgun_route.exemple", "expression",
+						`match_recipient(".*@samples.mailgun.org") and match_header("subject", "urgent")`),
+					resource.TestCheckResourceAttr("mailgun_route.exemple", "actions.#", "3"),
+					resource.TestCheckResourceAttr("mailgun_route.exemple", "actions.1", `store(notification="http://myhost.com/notify")`),
+					resource.TestCheckResourceAttr("mailgun_route.exemple", "forward.0.destination", "http://myhost.com/messages/"),
+				),
+			},
+		},
+	})
+}
+
 func TestRoute_importBasic(t *testing.T) {
 	var route mailgun.Route
 
@@ -91,7 +115,7 @@ func testAccRouteCheckExists(rn string, route *mailgun.Route) resource.TestCheck
 			return fmt.Errorf("routeID not set")
 		}
 
-		mg := testAccProvider.Meta().(*mailgun.MailgunImpl)
+		mg := testAccProvider.Meta().(*Config).Client("", "")
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 		defer cancel()
 
@@ -150,7 +174,7 @@ func testAccRouteCheckAttributes(rn string, route *mailgun.Route) resource.TestC
 
 func testAccRouteCheckDestroy(route *mailgun.Route) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		mg := testAccProvider.Meta().(*mailgun.MailgunImpl)
+		mg := testAccProvider.Meta().(*Config).Client("", "")
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 		defer cancel()
 
@@ -186,3 +210,22 @@ resource "mailgun_route" "exemple" {
         ]
 }
 `
+
+const testAccRouteConfig_structured = `
+resource "mailgun_route" "exemple" {
+        priority=5
+        description="ho ho hoh"
+        match_recipient=".*@samples.mailgun.org"
+        match_header {
+          name="subject"
+          regex="urgent"
+        }
+        forward {
+          destination="http://myhost.com/messages/"
+        }
+        store {
+          notify="http://myhost.com/notify"
+        }
+        stop=true
+}
+`
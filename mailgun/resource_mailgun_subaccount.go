@@ -0,0 +1,146 @@
+package mailgun
+
+import (
+	"context"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/mailgun/mailgun-go/v3"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const subaccountsEndpoint = "/v5/accounts/subaccounts"
+
+// subaccount is a subaccount as returned by the Mailgun accounts API.
+type subaccount struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type subaccountResponse struct {
+	Subaccount subaccount `json:"subaccount"`
+}
+
+type subaccountsListResponse struct {
+	Subaccounts []subaccount `json:"subaccounts"`
+	Total       int          `json:"total"`
+}
+
+func resourceMailgunSubaccount() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateSubaccount,
+		Update: UpdateSubaccount,
+		Delete: DeleteSubaccount,
+		Read:   ReadSubaccount,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"status": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateSubaccount(d *schema.ResourceData, meta interface{}) error {
+	mg := meta.(*Config).PrimaryClient()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	log.Printf("[DEBUG] creating mailgun subaccount: %s", d.Get("name").(string))
+
+	params := url.Values{}
+	params.Set("name", d.Get("name").(string))
+
+	var creationResponse subaccountResponse
+	err := apiRequest(ctx, mg, http.MethodPost, subaccountsEndpoint, params, &creationResponse)
+	if err != nil {
+		return newAPIError("creating", "mailgun_subaccount", err)
+	}
+
+	d.SetId(creationResponse.Subaccount.ID)
+
+	if !d.Get("enabled").(bool) {
+		err = setSubaccountEnabled(ctx, mg, d.Id(), false)
+		if err != nil {
+			return newAPIError("disabling", "mailgun_subaccount "+d.Id(), err)
+		}
+	}
+
+	return ReadSubaccount(d, meta)
+}
+
+func UpdateSubaccount(d *schema.ResourceData, meta interface{}) error {
+	mg := meta.(*Config).PrimaryClient()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	log.Printf("[DEBUG] updating mailgun subaccount: %s", d.Id())
+
+	if d.HasChange("enabled") {
+		err := setSubaccountEnabled(ctx, mg, d.Id(), d.Get("enabled").(bool))
+		if err != nil {
+			return newAPIError("updating", "mailgun_subaccount "+d.Id(), err)
+		}
+	}
+
+	return ReadSubaccount(d, meta)
+}
+
+// DeleteSubaccount disables the subaccount: Mailgun does not allow subaccounts to be deleted.
+func DeleteSubaccount(d *schema.ResourceData, meta interface{}) error {
+	mg := meta.(*Config).PrimaryClient()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	log.Printf("[DEBUG] Disabling mailgun subaccount: %s", d.Id())
+
+	if d.Get("status").(string) == "disabled" {
+		return nil
+	}
+
+	return newAPIError("disabling", "mailgun_subaccount "+d.Id(), setSubaccountEnabled(ctx, mg, d.Id(), false))
+}
+
+func ReadSubaccount(d *schema.ResourceData, meta interface{}) error {
+	mg := meta.(*Config).PrimaryClient()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	var response subaccountResponse
+	err := apiRequest(ctx, mg, http.MethodGet, subaccountsEndpoint+"/"+url.PathEscape(d.Id()), nil, &response)
+	if err != nil {
+		return newAPIError("reading", "mailgun_subaccount "+d.Id(), err)
+	}
+
+	d.Set("name", response.Subaccount.Name)
+	d.Set("status", response.Subaccount.Status)
+	d.Set("enabled", response.Subaccount.Status != "disabled")
+
+	return nil
+}
+
+func setSubaccountEnabled(ctx context.Context, mg *mailgun.MailgunImpl, id string, enabled bool) error {
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	return apiRequest(ctx, mg, http.MethodPost, subaccountsEndpoint+"/"+url.PathEscape(id)+"/"+action, nil, nil)
+}
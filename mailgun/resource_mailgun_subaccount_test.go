@@ -0,0 +1,82 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAccMailgunSubaccount_withUpdate(t *testing.T) {
+	var account subaccount
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSubaccountConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccSubaccountCheckExists("mailgun_subaccount.exemple", &account),
+					resource.TestCheckResourceAttr("mailgun_subaccount.exemple", "enabled", "true"),
+					resource.TestCheckResourceAttrPair("data.mailgun_subaccount.exemple", "subaccount_id", "mailgun_subaccount.exemple", "id"),
+				),
+			},
+			{
+				Config: testAccSubaccountConfig_disabled,
+				Check: resource.ComposeTestCheckFunc(
+					testAccSubaccountCheckExists("mailgun_subaccount.exemple", &account),
+					resource.TestCheckResourceAttr("mailgun_subaccount.exemple", "status", "disabled"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSubaccountCheckExists(rn string, account *subaccount) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[rn]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", rn)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("subaccountID not set")
+		}
+
+		mg := testAccProvider.Meta().(*Config).PrimaryClient()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+
+		var response subaccountResponse
+		err := apiRequest(ctx, mg, http.MethodGet, subaccountsEndpoint+"/"+url.PathEscape(rs.Primary.ID), nil, &response)
+		if err != nil {
+			return fmt.Errorf("error getting subaccount: %s", err)
+		}
+
+		*account = response.Subaccount
+
+		return nil
+	}
+}
+
+const testAccSubaccountConfig_basic = `
+resource "mailgun_subaccount" "exemple" {
+	name = "terraform-acceptance-test"
+}
+
+data "mailgun_subaccount" "exemple" {
+	name = mailgun_subaccount.exemple.name
+}
+`
+
+const testAccSubaccountConfig_disabled = `
+resource "mailgun_subaccount" "exemple" {
+	name    = "terraform-acceptance-test"
+	enabled = false
+}
+`
@@ -0,0 +1,182 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/mailgun/mailgun-go/v3"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func resourceMailgunTag() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateTag,
+		Update: UpdateTag,
+		Delete: DeleteTag,
+		Read:   ReadTag,
+		Importer: &schema.ResourceImporter{
+			State: ImportStateTag,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"domain": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"tag": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"first_seen": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"last_seen": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"subaccount_id": subaccountIDSchema(),
+		},
+	}
+}
+
+// tagID returns the id of a tag resource, e.g. domain.com/newsletter
+func tagID(domainName, tag string) string {
+	return domainName + "/" + tag
+}
+
+// parseTagID splits the id of a tag resource. Domain names cannot contain slashes, tags may.
+func parseTagID(id string) (string, string, error) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid mailgun tag id %q, expected <domain>/<tag>", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+func CreateTag(d *schema.ResourceData, meta interface{}) error {
+	domainName, err := meta.(*Config).ResolveDomain(d.Get("domain").(string))
+	if err != nil {
+		return fmt.Errorf("Error creating mailgun tag: %s", err)
+	}
+	tag := d.Get("tag").(string)
+
+	log.Printf("[DEBUG] creating mailgun tag %s for %s", tag, domainName)
+
+	err = updateTagDescription(d, meta, domainName, tag)
+	if err != nil {
+		apiErr := newAPIError("creating", "mailgun_tag "+tagID(domainName, tag), err).(*APIError)
+		if apiErr.StatusCode == http.StatusNotFound {
+			apiErr.Hint = "Mailgun creates tags when a message is first sent with them, so the tag cannot be managed before then"
+		}
+		return apiErr
+	}
+
+	d.SetId(tagID(domainName, tag))
+	return ReadTag(d, meta)
+}
+
+func UpdateTag(d *schema.ResourceData, meta interface{}) error {
+	domainName, tag, err := parseTagID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] updating mailgun tag: %s", d.Id())
+
+	if err := updateTagDescription(d, meta, domainName, tag); err != nil {
+		return newAPIError("updating", "mailgun_tag "+d.Id(), err)
+	}
+
+	return ReadTag(d, meta)
+}
+
+func updateTagDescription(d *schema.ResourceData, meta interface{}, domainName, tag string) error {
+	mg := resourceClient(d, meta, domainName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	params := url.Values{}
+	params.Set("description", d.Get("description").(string))
+	return apiRequest(ctx, mg, http.MethodPut, "/v3/"+url.PathEscape(domainName)+"/tags/"+url.PathEscape(tag), params, nil)
+}
+
+func DeleteTag(d *schema.ResourceData, meta interface{}) error {
+	domainName, tag, err := parseTagID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	mg := resourceClient(d, meta, domainName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	log.Printf("[DEBUG] Deleting mailgun tag: %s", d.Id())
+
+	err = mg.DeleteTag(ctx, tag)
+	if err != nil && mailgun.GetStatusFromErr(err) != http.StatusNotFound {
+		return newAPIError("deleting", "mailgun_tag "+d.Id(), err)
+	}
+
+	return nil
+}
+
+func ReadTag(d *schema.ResourceData, meta interface{}) error {
+	domainName, tag, err := parseTagID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	mg := resourceClient(d, meta, domainName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	tagItem, err := mg.GetTag(ctx, tag)
+	if mailgun.GetStatusFromErr(err) == http.StatusNotFound {
+		log.Printf("[WARN] mailgun tag %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return newAPIError("reading", "mailgun_tag "+d.Id(), err)
+	}
+
+	d.Set("domain", domainName)
+	d.Set("tag", tagItem.Value)
+	d.Set("description", tagItem.Description)
+	d.Set("first_seen", formatTagTime(tagItem.FirstSeen))
+	d.Set("last_seen", formatTagTime(tagItem.LastSeen))
+
+	return nil
+}
+
+// ImportStateTag checks the id of an imported tag, e.g. domain.com/newsletter
+func ImportStateTag(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if _, _, err := parseTagID(d.Id()); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func formatTagTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
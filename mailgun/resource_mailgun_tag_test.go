@@ -0,0 +1,308 @@
+package mailgun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mailgun/mailgun-go/v3"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseTagID(t *testing.T) {
+	cases := []struct {
+		id     string
+		domain string
+		tag    string
+		err    bool
+	}{
+		{id: "example.com/newsletter", domain: "example.com", tag: "newsletter"},
+		{id: "example.com/campaign/2019", domain: "example.com", tag: "campaign/2019"},
+		{id: "example.com", err: true},
+		{id: "example.com/", err: true},
+		{id: "/newsletter", err: true},
+	}
+
+	for _, c := range cases {
+		domain, tag, err := parseTagID(c.id)
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.id, err)
+			continue
+		}
+		if domain != c.domain || tag != c.tag {
+			t.Errorf("%s: expected %s and %s, got %s and %s", c.id, c.domain, c.tag, domain, tag)
+		}
+		if tagID(domain, tag) != c.id {
+			t.Errorf("%s: id round trip gave %s", c.id, tagID(domain, tag))
+		}
+	}
+}
+
+// The tag must have been used on MAILGUN_DOMAIN already, as Mailgun creates tags when messages are sent.
+// It is deleted, along with its stats, at the end of the test.
+func TestAccMailgunTag_withUpdate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccTagPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccTagCheckDestroy(testAccProvider),
+		Steps: []resource.TestStep{
+			{
+				Config: interpolateTerraformTemplateTag(testAccTagConfig_basic),
+				Check: resource.ComposeTestCheckFunc(
+					testAccTagCheckExists(testAccProvider, "mailgun_tag.exemple", "terraform acceptance test"),
+					resource.TestCheckResourceAttrSet("mailgun_tag.exemple", "first_seen"),
+				),
+			},
+			{
+				Config: interpolateTerraformTemplateTag(testAccTagConfig_update),
+				Check: resource.ComposeTestCheckFunc(
+					testAccTagCheckExists(testAccProvider, "mailgun_tag.exemple", "terraform acceptance test, updated"),
+				),
+			},
+			{
+				ResourceName:      "mailgun_tag.exemple",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestMailgunTag_fakeServer(t *testing.T) {
+	server := newFakeTagServer("example.com", "newsletter")
+	defer server.Close()
+	provider := fakeServerProvider(server)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    map[string]terraform.ResourceProvider{"mailgun": provider},
+		CheckDestroy: testAccTagCheckDestroy(provider),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccTagConfig_basic, "example.com", "newsletter"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccTagCheckExists(provider, "mailgun_tag.exemple", "terraform acceptance test"),
+					resource.TestCheckResourceAttr("mailgun_tag.exemple", "id", "example.com/newsletter"),
+					resource.TestCheckResourceAttr("mailgun_tag.exemple", "first_seen", "2019-10-19T16:00:00Z"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccTagConfig_update, "example.com", "newsletter"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccTagCheckExists(provider, "mailgun_tag.exemple", "terraform acceptance test, updated"),
+				),
+			},
+			{
+				ResourceName:      "mailgun_tag.exemple",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestMailgunTag_fakeServerUnused(t *testing.T) {
+	server := newFakeTagServer("example.com")
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceMailgunTag().Schema, map[string]interface{}{
+		"domain": "example.com",
+		"tag":    "newsletter",
+	})
+	err := CreateTag(d, &Config{APIKey: "key", apiBase: server.URL + "/v3"})
+	if err == nil || !strings.Contains(err.Error(), "first sent") {
+		t.Errorf("expected the creation of an unused tag to fail with a hint, got %v", err)
+	}
+}
+
+func TestReadTag_fakeServerMissing(t *testing.T) {
+	server := newFakeTagServer("example.com")
+	defer server.Close()
+
+	d := resourceMailgunTag().TestResourceData()
+	d.SetId("example.com/newsletter")
+	if err := ReadTag(d, &Config{APIKey: "key", apiBase: server.URL + "/v3"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Id() != "" {
+		t.Error("expected a missing tag to be removed from the state")
+	}
+}
+
+func testAccTagPreCheck(t *testing.T) {
+	testAccPreCheck(t)
+	if v := os.Getenv("MAILGUN_TAG"); v == "" {
+		t.Skip("MAILGUN_TAG must be set to a tag used on MAILGUN_DOMAIN for the tag acceptance tests")
+	}
+}
+
+func testAccTagCheckExists(provider *schema.Provider, rn string, description string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[rn]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", rn)
+		}
+
+		domainName, tag, err := parseTagID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		mg := provider.Meta().(*Config).Client(domainName, "")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+
+		gotTag, err := mg.GetTag(ctx, tag)
+		if err != nil {
+			return fmt.Errorf("error getting tag: %s", err)
+		}
+		if gotTag.Description != description {
+			return fmt.Errorf("expected the description %q, got %q", description, gotTag.Description)
+		}
+
+		return nil
+	}
+}
+
+func testAccTagCheckDestroy(provider *schema.Provider) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "mailgun_tag" {
+				continue
+			}
+
+			domainName, tag, err := parseTagID(rs.Primary.ID)
+			if err != nil {
+				return err
+			}
+
+			mg := provider.Meta().(*Config).Client(domainName, "")
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			_, err = mg.GetTag(ctx, tag)
+			cancel()
+			if err == nil {
+				return fmt.Errorf("tag %s still exists", rs.Primary.ID)
+			}
+			if mailgun.GetStatusFromErr(err) != http.StatusNotFound {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func interpolateTerraformTemplateTag(template string) string {
+	return fmt.Sprintf(template, os.Getenv("MAILGUN_DOMAIN"), os.Getenv("MAILGUN_TAG"))
+}
+
+// fakeServerProvider returns a provider sending its requests to server.
+func fakeServerProvider(server *httptest.Server) *schema.Provider {
+	provider := Provider().(*schema.Provider)
+	provider.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		return &Config{APIKey: "key", apiBase: server.URL + "/v3"}, nil
+	}
+	return provider
+}
+
+// newFakeTagServer serves the tags endpoints of domainName, which initially has the given tags,
+// all first seen on 2019-10-19 at 16:00 UTC. Lists are paged with the last tag of a page as cursor.
+func newFakeTagServer(domainName string, tags ...string) *httptest.Server {
+	var mu sync.Mutex
+	firstSeen := time.Date(2019, 10, 19, 16, 0, 0, 0, time.UTC)
+	store := make(map[string]mailgun.Tag, len(tags))
+	for _, tag := range tags {
+		store[tag] = mailgun.Tag{Value: tag, FirstSeen: &firstSeen, LastSeen: &firstSeen}
+	}
+
+	base := "/v3/" + domainName + "/tags"
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == base && r.Method == http.MethodGet {
+			query := r.URL.Query()
+			limit, _ := strconv.Atoi(query.Get("limit"))
+			if limit == 0 {
+				limit = 100
+			}
+			var names []string
+			for name := range store {
+				if strings.HasPrefix(name, query.Get("prefix")) && name > query.Get("tag") {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			if len(names) > limit {
+				names = names[:limit]
+			}
+
+			response := tagsListResponse{Items: []mailgun.Tag{}}
+			for _, name := range names {
+				response.Items = append(response.Items, store[name])
+			}
+			if len(names) > 0 {
+				next := query
+				next.Set("page", "next")
+				next.Set("tag", names[len(names)-1])
+				response.Paging.Next = server.URL + base + "?" + next.Encode()
+			}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		tag := strings.TrimPrefix(r.URL.Path, base+"/")
+		existing, ok := store[tag]
+		if !strings.HasPrefix(r.URL.Path, base+"/") || !ok {
+			http.Error(w, `{"message": "tag not found"}`, http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(existing)
+		case http.MethodPut:
+			existing.Description = r.FormValue("description")
+			store[tag] = existing
+			w.Write([]byte(`{"message": "Tag updated"}`))
+		case http.MethodDelete:
+			delete(store, tag)
+			w.Write([]byte(`{"message": "Tag deleted"}`))
+		default:
+			http.Error(w, `{"message": "method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+	}))
+	return server
+}
+
+const testAccTagConfig_basic = `
+resource "mailgun_tag" "exemple" {
+	domain      = "%s"
+	tag         = "%s"
+	description = "terraform acceptance test"
+}
+`
+
+const testAccTagConfig_update = `
+resource "mailgun_tag" "exemple" {
+	domain      = "%s"
+	tag         = "%s"
+	description = "terraform acceptance test, updated"
+}
+`
@@ -0,0 +1,337 @@
+package mailgun
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"strings"
+	"unicode"
+)
+
+// routeCall is a function call of a route expression or action, e.g. match_header("subject", ".*")
+// or store(notification="https://...").
+type routeCall struct {
+	name string
+	args []routeArg
+}
+
+type routeArg struct {
+	key   string
+	value string
+}
+
+var routeStringEscaper = strings.NewReplacer(`"`, `\"`)
+
+// quoteRouteString renders s as a double-quoted string literal of a route expression or action.
+// Only quotes are escaped: backslashes are left untouched as regular expressions rely on them.
+func quoteRouteString(s string) string {
+	return `"` + routeStringEscaper.Replace(s) + `"`
+}
+
+func (c routeCall) String() string {
+	args := make([]string, len(c.args))
+	for i, a := range c.args {
+		args[i] = quoteRouteString(a.value)
+		if a.key != "" {
+			args[i] = a.key + "=" + args[i]
+		}
+	}
+	return c.name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// routeExpression is the structured form of a route expression.
+type routeExpression struct {
+	matchRecipient string
+	headerName     string
+	headerRegex    string
+	hasHeader      bool
+	catchAll       bool
+}
+
+func (e routeExpression) String() string {
+	if e.catchAll {
+		return routeCall{name: "catch_all"}.String()
+	}
+
+	var calls []string
+	if e.matchRecipient != "" {
+		calls = append(calls, routeCall{name: "match_recipient", args: []routeArg{{value: e.matchRecipient}}}.String())
+	}
+	if e.hasHeader {
+		calls = append(calls, routeCall{name: "match_header", args: []routeArg{{value: e.headerName}, {value: e.headerRegex}}}.String())
+	}
+	return strings.Join(calls, " and ")
+}
+
+// routeActions is the structured form of the actions of a route.
+type routeActions struct {
+	forward     []string
+	store       bool
+	storeNotify string
+	stop        bool
+}
+
+func (a routeActions) Strings() []string {
+	var actions []string
+	for _, destination := range a.forward {
+		actions = append(actions, routeCall{name: "forward", args: []routeArg{{value: destination}}}.String())
+	}
+	if a.store {
+		store := routeCall{name: "store"}
+		if a.storeNotify != "" {
+			store.args = []routeArg{{key: "notification", value: a.storeNotify}}
+		}
+		actions = append(actions, store.String())
+	}
+	if a.stop {
+		actions = append(actions, routeCall{name: "stop"}.String())
+	}
+	return actions
+}
+
+// parseRouteExpression reads an expression made of match_recipient, match_header and catch_all calls
+// joined with "and". Other expressions have no structured form and are rejected.
+func parseRouteExpression(s string) (routeExpression, error) {
+	var e routeExpression
+
+	p := &routeParser{input: s}
+	for first := true; ; first = false {
+		p.skipSpaces()
+		if p.done() {
+			if first {
+				return e, fmt.Errorf("empty expression")
+			}
+			return e, nil
+		}
+		if !first && !p.consumeKeyword("and") {
+			return e, fmt.Errorf("unsupported operator at %q", p.rest())
+		}
+
+		call, err := p.parseCall()
+		if err != nil {
+			return e, err
+		}
+
+		switch {
+		case call.name == "match_recipient" && len(call.args) == 1 && e.matchRecipient == "":
+			e.matchRecipient = call.args[0].value
+		case call.name == "match_header" && len(call.args) == 2 && !e.hasHeader:
+			e.hasHeader = true
+			e.headerName = call.args[0].value
+			e.headerRegex = call.args[1].value
+		case call.name == "catch_all" && len(call.args) == 0 && first:
+			e.catchAll = true
+		default:
+			return e, fmt.Errorf("unsupported filter %s", call)
+		}
+
+		if e.catchAll && !first {
+			return e, fmt.Errorf("catch_all cannot be combined with other filters")
+		}
+	}
+}
+
+// parseRouteActions reads actions made of forward, store and stop calls.
+// Other actions have no structured form and are rejected.
+func parseRouteActions(actions []string) (routeActions, error) {
+	var a routeActions
+
+	for _, action := range actions {
+		p := &routeParser{input: action}
+		call, err := p.parseCall()
+		if err != nil {
+			return a, err
+		}
+		p.skipSpaces()
+		if !p.done() {
+			return a, fmt.Errorf("unexpected %q after action %s", p.rest(), call)
+		}
+
+		switch {
+		case call.name == "forward" && len(call.args) == 1 && call.args[0].key == "" && !a.store && !a.stop:
+			a.forward = append(a.forward, call.args[0].value)
+		case call.name == "store" && len(call.args) == 0 && !a.store && !a.stop:
+			a.store = true
+		case call.name == "store" && len(call.args) == 1 && !a.store && !a.stop &&
+			(call.args[0].key == "notification" || call.args[0].key == "notify"):
+			a.store = true
+			a.storeNotify = call.args[0].value
+		case call.name == "stop" && len(call.args) == 0 && !a.stop:
+			a.stop = true
+		default:
+			return a, fmt.Errorf("unsupported action %s", action)
+		}
+	}
+	return a, nil
+}
+
+// normalizeRouteCode returns the canonical form of a route expression or action: string literals
+// double quoted, no whitespace around punctuation, single spaces elsewhere, lower case functions and operators.
+// Mailgun may rewrite expressions and actions this way, so two values with the same canonical form are equivalent.
+// Values which cannot be scanned are only trimmed.
+func normalizeRouteCode(s string) string {
+	p := &routeParser{input: strings.TrimSpace(s)}
+	var b strings.Builder
+	var last byte
+	space := false
+	for !p.done() {
+		c := p.input[p.pos]
+		if unicode.IsSpace(rune(c)) {
+			space = true
+			p.pos++
+			continue
+		}
+
+		if space && last != 0 && !isRoutePunctuation(c) && !isRoutePunctuation(last) {
+			b.WriteByte(' ')
+		}
+		space = false
+
+		switch {
+		case c == '"' || c == '\'':
+			value, err := p.parseString()
+			if err != nil {
+				return p.input
+			}
+			b.WriteString(quoteRouteString(value))
+			last = '"'
+		default:
+			last = byte(unicode.ToLower(rune(c)))
+			b.WriteByte(last)
+			p.pos++
+		}
+	}
+	return b.String()
+}
+
+func isRoutePunctuation(c byte) bool {
+	return strings.IndexByte("(),=", c) >= 0
+}
+
+// suppressRouteExpressionDiff ignores cosmetic differences in route expressions.
+func suppressRouteExpressionDiff(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeRouteCode(old) == normalizeRouteCode(new)
+}
+
+// suppressRouteActionsDiff ignores cosmetic differences in each of the route actions.
+func suppressRouteActionsDiff(k, old, new string, d *schema.ResourceData) bool {
+	if strings.HasSuffix(k, ".#") {
+		return old == new
+	}
+	return normalizeRouteCode(old) == normalizeRouteCode(new)
+}
+
+// routeParser is a minimal scanner for the function calls of route expressions and actions.
+// String literals may be single or double quoted; a backslash escapes a quote
+// and is kept as is before any other character.
+type routeParser struct {
+	input string
+	pos   int
+}
+
+func (p *routeParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *routeParser) rest() string {
+	return p.input[p.pos:]
+}
+
+func (p *routeParser) skipSpaces() {
+	for !p.done() && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *routeParser) consume(c byte) bool {
+	p.skipSpaces()
+	if !p.done() && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *routeParser) consumeKeyword(keyword string) bool {
+	p.skipSpaces()
+	start := p.pos
+	if p.identifier() == keyword {
+		return true
+	}
+	p.pos = start
+	return false
+}
+
+func (p *routeParser) identifier() string {
+	p.skipSpaces()
+	start := p.pos
+	for !p.done() {
+		c := p.input[p.pos]
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return strings.ToLower(p.input[start:p.pos])
+}
+
+func (p *routeParser) parseString() (string, error) {
+	p.skipSpaces()
+	if p.done() || (p.input[p.pos] != '"' && p.input[p.pos] != '\'') {
+		return "", fmt.Errorf("expected a string at %q", p.rest())
+	}
+	quote := p.input[p.pos]
+	p.pos++
+
+	var value strings.Builder
+	for !p.done() {
+		c := p.input[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && !p.done() && (p.input[p.pos] == '"' || p.input[p.pos] == '\''):
+			value.WriteByte(p.input[p.pos])
+			p.pos++
+		case c == quote:
+			return value.String(), nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string in %q", p.input)
+}
+
+func (p *routeParser) parseCall() (routeCall, error) {
+	call := routeCall{name: p.identifier()}
+	if call.name == "" {
+		return call, fmt.Errorf("expected a function at %q", p.rest())
+	}
+	if !p.consume('(') {
+		return call, fmt.Errorf("expected ( after %s", call.name)
+	}
+	if p.consume(')') {
+		return call, nil
+	}
+
+	for {
+		var arg routeArg
+		start := p.pos
+		if key := p.identifier(); key != "" && p.consume('=') {
+			arg.key = key
+		} else {
+			p.pos = start
+		}
+
+		value, err := p.parseString()
+		if err != nil {
+			return call, err
+		}
+		arg.value = value
+		call.args = append(call.args, arg)
+
+		if p.consume(')') {
+			return call, nil
+		}
+		if !p.consume(',') {
+			return call, fmt.Errorf("expected , or ) at %q", p.rest())
+		}
+	}
+}
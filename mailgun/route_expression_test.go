@@ -0,0 +1,148 @@
+package mailgun
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRouteExpression_String(t *testing.T) {
+	cases := []struct {
+		expression routeExpression
+		expected   string
+	}{
+		{routeExpression{matchRecipient: ".*@example.com"}, `match_recipient(".*@example.com")`},
+		{routeExpression{hasHeader: true, headerName: "subject", headerRegex: `\[urgent\]`}, `match_header("subject", "\[urgent\]")`},
+		{
+			routeExpression{matchRecipient: "bob@example.com", hasHeader: true, headerName: "subject", headerRegex: `say "hi"`},
+			`match_recipient("bob@example.com") and match_header("subject", "say \"hi\"")`,
+		},
+		{routeExpression{catchAll: true}, `catch_all()`},
+	}
+
+	for _, c := range cases {
+		if got := c.expression.String(); got != c.expected {
+			t.Errorf("expected %s, got %s", c.expected, got)
+		}
+		parsed, err := parseRouteExpression(c.expected)
+		if err != nil {
+			t.Errorf("error parsing %s: %s", c.expected, err)
+		} else if parsed != c.expression {
+			t.Errorf("%s parsed as %+v, expected %+v", c.expected, parsed, c.expression)
+		}
+	}
+}
+
+func TestParseRouteExpression(t *testing.T) {
+	valid := map[string]routeExpression{
+		`match_recipient('.*@example.com')`:                                    {matchRecipient: ".*@example.com"},
+		` MATCH_RECIPIENT( ".*@example.com" ) `:                                {matchRecipient: ".*@example.com"},
+		`match_header('subject', 'it\'s urgent') and match_recipient("a@b.c")`: {matchRecipient: "a@b.c", hasHeader: true, headerName: "subject", headerRegex: "it's urgent"},
+		`catch_all()`: {catchAll: true},
+	}
+	for s, expected := range valid {
+		got, err := parseRouteExpression(s)
+		if err != nil {
+			t.Errorf("error parsing %s: %s", s, err)
+		} else if got != expected {
+			t.Errorf("%s parsed as %+v, expected %+v", s, got, expected)
+		}
+	}
+
+	invalid := []string{
+		``,
+		`match_recipient("a@b.c") or match_recipient("d@e.f")`,
+		`match_recipient("a@b.c") and match_recipient("d@e.f")`,
+		`match_recipient("a@b.c") and catch_all()`,
+		`match_recipient("a@b.c"`,
+		`match_recipient("a@b.c)`,
+		`not(match_recipient("a@b.c"))`,
+	}
+	for _, s := range invalid {
+		if _, err := parseRouteExpression(s); err == nil {
+			t.Errorf("expected an error parsing %s", s)
+		}
+	}
+}
+
+func TestRouteActions(t *testing.T) {
+	actions := routeActions{
+		forward:     []string{"https://example.com/messages", "bob@example.com"},
+		store:       true,
+		storeNotify: "https://example.com/notify",
+		stop:        true,
+	}
+	expected := []string{
+		`forward("https://example.com/messages")`,
+		`forward("bob@example.com")`,
+		`store(notification="https://example.com/notify")`,
+		`stop()`,
+	}
+
+	if got := actions.Strings(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	parsed, err := parseRouteActions(expected)
+	if err != nil {
+		t.Fatalf("error parsing %v: %s", expected, err)
+	}
+	if !reflect.DeepEqual(parsed, actions) {
+		t.Errorf("%v parsed as %+v, expected %+v", expected, parsed, actions)
+	}
+
+	parsed, err = parseRouteActions([]string{`forward('bob@example.com')`, `store(notify='https://example.com/notify')`})
+	if err != nil {
+		t.Fatalf("error parsing single quoted actions: %s", err)
+	}
+	if parsed.forward[0] != "bob@example.com" || !parsed.store || parsed.storeNotify != "https://example.com/notify" {
+		t.Errorf("unexpected single quoted actions %+v", parsed)
+	}
+
+	for _, invalid := range [][]string{
+		{`drop()`},
+		{`stop()`, `forward("bob@example.com")`},
+		{`store()`, `store()`},
+		{`forward(to="bob@example.com")`},
+		{`stop() stop()`},
+	} {
+		if _, err := parseRouteActions(invalid); err == nil {
+			t.Errorf("expected an error parsing %v", invalid)
+		}
+	}
+}
+
+func TestNormalizeRouteCode(t *testing.T) {
+	equivalent := [][2]string{
+		{`match_recipient('.*@example.com')`, `match_recipient(".*@example.com")`},
+		{` match_recipient( ".*@example.com" )  AND  match_header('subject','it\'s') `, `match_recipient(".*@example.com") and match_header("subject", "it's")`},
+		{`Catch_All()`, `catch_all()`},
+		{`forward('https://example.com/messages')`, `forward("https://example.com/messages")`},
+		{`store(notification = 'https://example.com')`, `store(notification="https://example.com")`},
+		{`match_header("subject", "\[urgent\]")`, `match_header('subject', '\[urgent\]')`},
+		{`match_recipient("unterminated`, ` match_recipient("unterminated`},
+	}
+	for _, c := range equivalent {
+		if a, b := normalizeRouteCode(c[0]), normalizeRouteCode(c[1]); a != b {
+			t.Errorf("expected %s and %s to be equivalent, normalized as %s and %s", c[0], c[1], a, b)
+		}
+	}
+
+	different := [][2]string{
+		{`match_recipient("A@example.com")`, `match_recipient("a@example.com")`},
+		{`match_recipient("a b")`, `match_recipient("a  b")`},
+		{`forward("a@example.com")`, `forward("b@example.com")`},
+	}
+	for _, c := range different {
+		if normalizeRouteCode(c[0]) == normalizeRouteCode(c[1]) {
+			t.Errorf("expected %s and %s to be different", c[0], c[1])
+		}
+	}
+}
+
+func TestSuppressRouteActionsDiff(t *testing.T) {
+	if !suppressRouteActionsDiff("actions.0", `forward('a@example.com')`, `forward("a@example.com")`, nil) {
+		t.Error("expected quote style differences to be suppressed")
+	}
+	if suppressRouteActionsDiff("actions.#", "1", "2", nil) {
+		t.Error("expected a different number of actions not to be suppressed")
+	}
+}
@@ -0,0 +1,182 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// spfLookupLimit is the maximum number of DNS lookups an SPF evaluation may trigger (RFC 7208 4.6.4).
+const spfLookupLimit = 10
+
+// spfRecord is a parsed SPF record.
+type spfRecord struct {
+	mechanisms []string
+	all        string
+	modifiers  []string
+}
+
+// parseSPF splits an SPF record in its mechanisms, all mechanism and modifiers.
+// The version is optional, so that bare lists of mechanisms can be parsed too.
+func parseSPF(s string) (spfRecord, error) {
+	var r spfRecord
+	for i, term := range strings.Fields(s) {
+		lower := strings.ToLower(term)
+		switch {
+		case lower == "v=spf1":
+			if i != 0 {
+				return r, fmt.Errorf("unexpected version in %q", s)
+			}
+		case strings.HasPrefix(lower, "v="):
+			return r, fmt.Errorf("unsupported version %s", term)
+		case strings.TrimLeft(lower, "+-~?") == "all":
+			r.all = canonicalSPFTerm(term)
+		case strings.Contains(lower, "=") && !strings.ContainsAny(lower[:strings.Index(lower, "=")], ":/"):
+			r.modifiers = append(r.modifiers, term)
+		default:
+			r.mechanisms = append(r.mechanisms, canonicalSPFTerm(term))
+		}
+	}
+	return r, nil
+}
+
+// canonicalSPFTerm drops the default + qualifier and lower cases the mechanism name,
+// so that equivalent mechanisms compare equal.
+func canonicalSPFTerm(term string) string {
+	term = strings.TrimPrefix(term, "+")
+	qualifier := ""
+	if term != "" && strings.ContainsAny(term[:1], "-~?") {
+		qualifier, term = term[:1], term[1:]
+	}
+	name, rest := term, ""
+	if i := strings.IndexAny(term, ":/"); i >= 0 {
+		name, rest = term[:i], term[i:]
+	}
+	return qualifier + strings.ToLower(name) + rest
+}
+
+// mergeSPF merges SPF records in a single one: the mechanisms of each record in order, without
+// duplicates, then the all mechanism of the first record which has one, then the modifiers.
+// The redirect modifier is dropped when there is an all mechanism, as it would be ignored.
+func mergeSPF(records ...spfRecord) spfRecord {
+	var merged spfRecord
+	seen := make(map[string]bool)
+	for _, r := range records {
+		for _, m := range r.mechanisms {
+			key := strings.ToLower(m)
+			if !seen[key] {
+				seen[key] = true
+				merged.mechanisms = append(merged.mechanisms, m)
+			}
+		}
+		if merged.all == "" {
+			merged.all = r.all
+		}
+	}
+
+	seen = make(map[string]bool)
+	for _, r := range records {
+		for _, m := range r.modifiers {
+			name := strings.ToLower(m[:strings.Index(m, "=")])
+			if seen[name] || (name == "redirect" && merged.all != "") {
+				continue
+			}
+			seen[name] = true
+			merged.modifiers = append(merged.modifiers, m)
+		}
+	}
+	return merged
+}
+
+func (r spfRecord) String() string {
+	terms := append([]string{"v=spf1"}, r.mechanisms...)
+	if r.all != "" {
+		terms = append(terms, r.all)
+	}
+	return strings.Join(append(terms, r.modifiers...), " ")
+}
+
+// lookups counts the mechanisms and modifiers of r itself which trigger DNS lookups.
+// The lookups of the included records are counted by resolveSPFLookups.
+func (r spfRecord) lookups() int {
+	count := 0
+	for _, m := range r.mechanisms {
+		name := strings.TrimLeft(m, "-~?")
+		if i := strings.IndexAny(name, ":/"); i >= 0 {
+			name = name[:i]
+		}
+		switch name {
+		case "include", "a", "mx", "ptr", "exists":
+			count++
+		}
+	}
+	for _, m := range r.modifiers {
+		if strings.HasPrefix(strings.ToLower(m), "redirect=") {
+			count++
+		}
+	}
+	return count
+}
+
+// spfTXTLookup resolves the TXT records of a name, e.g. net.Resolver.LookupTXT.
+type spfTXTLookup func(ctx context.Context, name string) ([]string, error)
+
+// resolveSPFLookups counts the DNS lookups an evaluation of r triggers, including those of the records
+// of its include mechanisms and redirect modifier, resolved recursively with lookupTXT (RFC 7208 4.6.4).
+// The resolution stops as soon as the limit is exceeded. Targets using macros, or which cannot be
+// resolved, are counted without their own lookups.
+func resolveSPFLookups(ctx context.Context, lookupTXT spfTXTLookup, r spfRecord) int {
+	count := 0
+	resolveSPFRecordLookups(ctx, lookupTXT, r, &count, 0)
+	return count
+}
+
+func resolveSPFRecordLookups(ctx context.Context, lookupTXT spfTXTLookup, r spfRecord, count *int, depth int) {
+	*count += r.lookups()
+	for _, target := range r.lookupTargets() {
+		if *count > spfLookupLimit || depth >= spfLookupLimit {
+			return
+		}
+		if strings.Contains(target, "%") {
+			continue
+		}
+		included, err := lookupSPF(ctx, lookupTXT, target)
+		if err != nil {
+			log.Printf("[WARN] not counting the DNS lookups of the SPF record of %s: %s", target, err)
+			continue
+		}
+		resolveSPFRecordLookups(ctx, lookupTXT, included, count, depth+1)
+	}
+}
+
+// lookupTargets returns the domains of the include mechanisms and redirect modifier of r.
+func (r spfRecord) lookupTargets() []string {
+	var targets []string
+	for _, m := range r.mechanisms {
+		name := strings.TrimLeft(m, "-~?")
+		if strings.HasPrefix(name, "include:") {
+			targets = append(targets, name[len("include:"):])
+		}
+	}
+	for _, m := range r.modifiers {
+		if strings.HasPrefix(strings.ToLower(m), "redirect=") {
+			targets = append(targets, m[len("redirect="):])
+		}
+	}
+	return targets
+}
+
+// lookupSPF returns the SPF record published by domain.
+func lookupSPF(ctx context.Context, lookupTXT spfTXTLookup, domain string) (spfRecord, error) {
+	txts, err := lookupTXT(ctx, domain)
+	if err != nil {
+		return spfRecord{}, err
+	}
+	for _, txt := range txts {
+		if lower := strings.ToLower(txt); lower == "v=spf1" || strings.HasPrefix(lower, "v=spf1 ") {
+			return parseSPF(txt)
+		}
+	}
+	return spfRecord{}, fmt.Errorf("no SPF record")
+}
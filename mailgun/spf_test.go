@@ -0,0 +1,120 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseSPF(t *testing.T) {
+	r, err := parseSPF("v=spf1 +include:_spf.google.com MX ip4:192.0.2.0/24 -exists:%{i}.example.com ~all redirect=example.com")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := spfRecord{
+		mechanisms: []string{"include:_spf.google.com", "mx", "ip4:192.0.2.0/24", "-exists:%{i}.example.com"},
+		all:        "~all",
+		modifiers:  []string{"redirect=example.com"},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %+v, got %+v", expected, r)
+	}
+
+	for _, invalid := range []string{"v=spf2 mx", "mx v=spf1"} {
+		if _, err := parseSPF(invalid); err == nil {
+			t.Errorf("expected an error parsing %s", invalid)
+		}
+	}
+}
+
+func TestMergeSPF(t *testing.T) {
+	cases := []struct {
+		records  []string
+		expected string
+	}{
+		{
+			[]string{"v=spf1 include:_spf.google.com -all", "v=spf1 include:mailgun.org ~all"},
+			"v=spf1 include:_spf.google.com include:mailgun.org -all",
+		},
+		{
+			[]string{"", "v=spf1 include:mailgun.org ~all"},
+			"v=spf1 include:mailgun.org ~all",
+		},
+		{
+			[]string{"v=spf1 +include:MAILGUN.org ip4:192.0.2.1", "v=spf1 include:mailgun.org ~all", "ip4:192.0.2.1 a"},
+			"v=spf1 include:MAILGUN.org ip4:192.0.2.1 a ~all",
+		},
+		{
+			[]string{"v=spf1 redirect=_spf.example.com", "v=spf1 include:mailgun.org ~all"},
+			"v=spf1 include:mailgun.org ~all",
+		},
+		{
+			[]string{"v=spf1 mx exp=explain.example.com", "v=spf1 include:mailgun.org"},
+			"v=spf1 mx include:mailgun.org exp=explain.example.com",
+		},
+	}
+
+	for _, c := range cases {
+		var records []spfRecord
+		for _, s := range c.records {
+			r, err := parseSPF(s)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			records = append(records, r)
+		}
+		if got := mergeSPF(records...).String(); got != c.expected {
+			t.Errorf("merging %q: expected %s, got %s", c.records, c.expected, got)
+		}
+	}
+}
+
+func TestSPFLookups(t *testing.T) {
+	r, err := parseSPF("v=spf1 include:a.example.com include:b.example.com a mx:example.com ptr exists:x.example.com ip4:192.0.2.1 ip6:2001:db8::1 ~all redirect=c.example.com")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if lookups := r.lookups(); lookups != 7 {
+		t.Errorf("expected 7 lookups, got %d", lookups)
+	}
+}
+
+func TestResolveSPFLookups(t *testing.T) {
+	txts := map[string][]string{
+		"mailgun.org":      {"google-site-verification=x", "v=spf1 include:_spf.mailgun.org include:_spf.eu.mailgun.org ~all"},
+		"_spf.mailgun.org": {"v=spf1 ip4:192.0.2.0/24 a:mx.mailgun.org ~all"},
+		// _spf.eu.mailgun.org does not resolve
+		"loop.example.com": {"v=spf1 include:loop.example.com -all"},
+		"many.example.com": {"v=spf1 a mx ptr exists:x.example.com a:a.example.com a:b.example.com a:c.example.com -all"},
+	}
+	lookupTXT := func(ctx context.Context, name string) ([]string, error) {
+		if txt, ok := txts[name]; ok {
+			return txt, nil
+		}
+		return nil, fmt.Errorf("no such host %s", name)
+	}
+
+	cases := []struct {
+		record   string
+		expected int
+	}{
+		// include:mailgun.org, its two includes and the a mechanism of _spf.mailgun.org
+		{"v=spf1 include:mailgun.org ip4:192.0.2.1 ~all", 4},
+		{"v=spf1 include:%{d}.example.com ~all", 1},
+		// stops as soon as the limit is exceeded
+		{"v=spf1 include:loop.example.com -all", spfLookupLimit + 1},
+		// 3 at the top, 7 in many.example.com, then the 2 includes of mailgun.org exceed the limit
+		{"v=spf1 mx include:many.example.com include:mailgun.org -all", 12},
+	}
+
+	for _, c := range cases {
+		r, err := parseSPF(c.record)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got := resolveSPFLookups(context.Background(), lookupTXT, r); got != c.expected {
+			t.Errorf("%s: expected %d lookups, got %d", c.record, c.expected, got)
+		}
+	}
+}
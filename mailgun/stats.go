@@ -0,0 +1,155 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/mailgun/mailgun-go/v3"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// statsEvents are the events the stats data sources count.
+var statsEvents = []string{"accepted", "delivered", "failed", "opened", "clicked", "unsubscribed", "complained", "stored"}
+
+// statsItem holds the counters of a period of the stats API. It is decoded by hand rather than
+// through mailgun-go, which drops the total of the temporary failures and cannot read tag stats.
+type statsItem struct {
+	Time     string `json:"time"`
+	Accepted struct {
+		Total int `json:"total"`
+	} `json:"accepted"`
+	Delivered struct {
+		Total int `json:"total"`
+	} `json:"delivered"`
+	Failed struct {
+		Temporary struct {
+			Espblock int `json:"espblock"`
+			Total    int `json:"total"`
+		} `json:"temporary"`
+		Permanent struct {
+			Total int `json:"total"`
+		} `json:"permanent"`
+	} `json:"failed"`
+	Stored       mailgun.Total `json:"stored"`
+	Opened       mailgun.Total `json:"opened"`
+	Clicked      mailgun.Total `json:"clicked"`
+	Unsubscribed mailgun.Total `json:"unsubscribed"`
+	Complained   mailgun.Total `json:"complained"`
+}
+
+type statsResponse struct {
+	Start      string      `json:"start"`
+	End        string      `json:"end"`
+	Resolution string      `json:"resolution"`
+	Stats      []statsItem `json:"stats"`
+}
+
+// counters returns the counters of s keyed by their attribute names.
+func (s statsItem) counters() map[string]int {
+	temporary := s.Failed.Temporary.Total
+	if temporary == 0 {
+		temporary = s.Failed.Temporary.Espblock
+	}
+	return map[string]int{
+		"accepted":         s.Accepted.Total,
+		"delivered":        s.Delivered.Total,
+		"failed_temporary": temporary,
+		"failed_permanent": s.Failed.Permanent.Total,
+		"opened":           s.Opened.Total,
+		"clicked":          s.Clicked.Total,
+		"unsubscribed":     s.Unsubscribed.Total,
+		"complained":       s.Complained.Total,
+		"stored":           s.Stored.Total,
+	}
+}
+
+var statsDuration = regexp.MustCompile(`^[1-9][0-9]*[hdm]$`)
+
+// statsSchema returns the schema of a stats data source, with the arguments of the scope of the stats.
+func statsSchema(scope map[string]*schema.Schema) map[string]*schema.Schema {
+	counters := func() map[string]*schema.Schema {
+		s := make(map[string]*schema.Schema)
+		for name := range (statsItem{}).counters() {
+			s[name] = &schema.Schema{Type: schema.TypeInt, Computed: true}
+		}
+		return s
+	}
+
+	periodSchema := counters()
+	periodSchema["time"] = &schema.Schema{Type: schema.TypeString, Computed: true}
+
+	s := counters()
+	s["domain"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Computed: true,
+	}
+	s["duration"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "7d",
+		ValidateFunc: validation.StringMatch(statsDuration, "must be a number of hours, days or months such as 24h, 7d or 1m"),
+	}
+	s["resolution"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "day",
+		ValidateFunc: validation.StringInSlice([]string{"hour", "day", "month"}, false),
+	}
+	s["start"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+	s["end"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+	s["periods"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem:     &schema.Resource{Schema: periodSchema},
+	}
+	s["subaccount_id"] = subaccountIDSchema()
+	for k, v := range scope {
+		s[k] = v
+	}
+	return s
+}
+
+// readStats reads the stats at path and sets their totals and periods on d.
+func readStats(ctx context.Context, mg *mailgun.MailgunImpl, path string, d *schema.ResourceData) error {
+	params := url.Values{}
+	params.Set("duration", d.Get("duration").(string))
+	params.Set("resolution", d.Get("resolution").(string))
+	for _, e := range statsEvents {
+		params.Add("event", e)
+	}
+
+	var response statsResponse
+	if err := apiRequest(ctx, mg, http.MethodGet, path, params, &response); err != nil {
+		return err
+	}
+
+	totals := make(map[string]int)
+	periods := make([]map[string]interface{}, len(response.Stats))
+	for i, s := range response.Stats {
+		periods[i] = map[string]interface{}{"time": s.Time}
+		for name, count := range s.counters() {
+			totals[name] += count
+			periods[i][name] = count
+		}
+	}
+
+	for name := range (statsItem{}).counters() {
+		if err := d.Set(name, totals[name]); err != nil {
+			return fmt.Errorf("while setting %s: %s", name, err)
+		}
+	}
+	d.Set("start", response.Start)
+	d.Set("end", response.End)
+	d.Set("periods", periods)
+	return nil
+}
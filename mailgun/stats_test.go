@@ -0,0 +1,73 @@
+package mailgun
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/mailgun/mailgun-go/v3"
+)
+
+func TestReadStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/example.com/tags/newsletter/stats" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("duration") != "2d" || query.Get("resolution") != "day" || len(query["event"]) != len(statsEvents) {
+			http.Error(w, fmt.Sprintf("unexpected query %s", r.URL.RawQuery), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"start": "Fri, 18 Oct 2019 00:00:00 UTC", "end": "Sat, 19 Oct 2019 00:00:00 UTC", "resolution": "day", "stats": [
+			{"time": "Fri, 18 Oct 2019 00:00:00 UTC", "accepted": {"total": 100}, "delivered": {"total": 90},
+			 "failed": {"temporary": {"espblock": 3}, "permanent": {"bounce": 5, "total": 7}},
+			 "opened": {"total": 40}, "clicked": {"total": 10}, "complained": {"total": 1}, "unsubscribed": {"total": 2}},
+			{"time": "Sat, 19 Oct 2019 00:00:00 UTC", "accepted": {"total": 50}, "delivered": {"total": 48},
+			 "failed": {"temporary": {"espblock": 1, "total": 2}, "permanent": {"total": 0}},
+			 "opened": {"total": 20}, "stored": {"total": 4}}
+		]}`)
+	}))
+	defer server.Close()
+
+	mg := mailgun.NewMailgun("example.com", "key")
+	mg.SetAPIBase(server.URL + "/v3")
+
+	d := schema.TestResourceDataRaw(t, dataSourceMailgunTagStats().Schema, map[string]interface{}{
+		"tag":      "newsletter",
+		"duration": "2d",
+	})
+	if err := readStats(context.Background(), mg, "/v3/example.com/tags/newsletter/stats", d); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]int{
+		"accepted":         150,
+		"delivered":        138,
+		"failed_temporary": 5,
+		"failed_permanent": 7,
+		"opened":           60,
+		"clicked":          10,
+		"complained":       1,
+		"unsubscribed":     2,
+		"stored":           4,
+	}
+	for name, value := range expected {
+		if got := d.Get(name).(int); got != value {
+			t.Errorf("expected %s to be %d, got %d", name, value, got)
+		}
+	}
+	if got := d.Get("periods.#").(int); got != 2 {
+		t.Errorf("expected 2 periods, got %d", got)
+	}
+	if got := d.Get("periods.1.failed_temporary").(int); got != 2 {
+		t.Errorf("expected the total of temporary failures to be preferred, got %d", got)
+	}
+	if got := d.Get("start").(string); got != "Fri, 18 Oct 2019 00:00:00 UTC" {
+		t.Errorf("unexpected start %s", got)
+	}
+}
@@ -1,11 +1,59 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/fretlink/terraform-provider-mailgun/mailgun"
 	"github.com/hashicorp/terraform/plugin"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(export(os.Args[2:]))
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: mailgun.Provider})
 }
+
+// export writes the Terraform configuration of an existing Mailgun account,
+// read with the credentials of the provider environment variables.
+func export(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s export [options]\n\n"+
+			"Writes the Terraform configuration of the domains and routes of a Mailgun account, along with\n"+
+			"their imports. The account is read with MAILGUN_APIKEY, MAILGUN_SUBACCOUNT_ID and MAILGUN_REGION.\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	dir := flags.String("dir", ".", "directory to write the configuration to")
+	domains := flags.String("domains", "", "comma separated domains to export, all of them when empty")
+	importBlocks := flags.Bool("import-blocks", false, "write import blocks (Terraform 1.5+) instead of an import.sh script")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	config := &mailgun.Config{
+		APIKey:       os.Getenv("MAILGUN_APIKEY"),
+		SubaccountID: os.Getenv("MAILGUN_SUBACCOUNT_ID"),
+		Region:       os.Getenv("MAILGUN_REGION"),
+		UserAgent:    "terraform-provider-mailgun/" + mailgun.ProviderVersion + " export",
+	}
+	if config.APIKey == "" {
+		fmt.Fprintln(os.Stderr, "MAILGUN_APIKEY must be set")
+		return 1
+	}
+
+	opts := mailgun.ExportOptions{Dir: *dir, ImportBlocks: *importBlocks}
+	if *domains != "" {
+		opts.Domains = strings.Split(*domains, ",")
+	}
+	if err := mailgun.Export(config, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}